@@ -19,7 +19,9 @@ limitations under the License.
 package subctl
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -35,6 +37,7 @@ import (
 
 var (
 	diagnoseFirewallOptions diagnose.FirewallOptions
+	diagnoseOutput          string
 
 	diagnoseRestConfigProducer = restconfig.NewProducer().WithDefaultNamespace(constants.OperatorNamespace).WithInClusterFlag()
 
@@ -42,6 +45,8 @@ var (
 							WithDefaultNamespace(constants.OperatorNamespace).WithPrefixedContext("remote")
 	diagnoseFirewallNatDiscoveryRestConfigProducer = restconfig.NewProducer().
 							WithDefaultNamespace(constants.OperatorNamespace).WithPrefixedContext("remote")
+	diagnoseFirewallNATTRestConfigProducer = restconfig.NewProducer().
+						WithDefaultNamespace(constants.OperatorNamespace).WithPrefixedContext("remote")
 
 	diagnoseCmd = &cobra.Command{
 		Use:   "diagnose",
@@ -54,8 +59,7 @@ var (
 		Short: "Check the CNI network plugin",
 		Long:  "This command checks if the detected CNI network plugin is supported by Submariner.",
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				diagnoseRestConfigProducer.RunOnAllContexts(restconfig.IfConnectivityInstalled(diagnose.CNIConfig), cli.NewReporter()))
+			exit.OnError(runDiagnose(restconfig.IfConnectivityInstalled(diagnose.CNIConfig)))
 		},
 	}
 
@@ -64,8 +68,36 @@ var (
 		Short: "Check the Gateway connections",
 		Long:  "This command checks that the Gateway connections to other clusters are all established",
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				diagnoseRestConfigProducer.RunOnAllContexts(restconfig.IfConnectivityInstalled(diagnose.Connections), cli.NewReporter()))
+			exit.OnError(runDiagnose(restconfig.IfConnectivityInstalled(diagnose.Connections)))
+		},
+	}
+
+	diagnoseCIDRsCmd = &cobra.Command{
+		Use:   "cidrs",
+		Short: "Check the CIDRs of the joined clusters",
+		Long:  "This command checks that the CIDRs of the clusters joined to the broker don't overlap.",
+		Run: func(command *cobra.Command, args []string) {
+			exit.OnError(runDiagnose(restconfig.IfConnectivityInstalled(diagnose.ClusterCIDRs)))
+		},
+	}
+
+	diagnoseMTUCmd = &cobra.Command{
+		Use:   "mtu",
+		Short: "Check the gateway MTUs",
+		Long: "This command checks that the gateway tunnel MTUs are consistent across clusters and leave room for the " +
+			"encapsulation within the underlay MTU.",
+		Run: func(command *cobra.Command, args []string) {
+			exit.OnError(runDiagnose(restconfig.IfConnectivityInstalled(diagnose.NewMTUChecker().Check)))
+		},
+	}
+
+	diagnoseClockSkewCmd = &cobra.Command{
+		Use:   "clock-skew",
+		Short: "Check the clock skew of the Gateway nodes",
+		Long: "This command checks that the clock of the Gateway node of each cluster is within 5 seconds of the local machine's, " +
+			"and reports the measured offset.",
+		Run: func(command *cobra.Command, args []string) {
+			exit.OnError(runDiagnose(restconfig.IfConnectivityInstalled(diagnose.ClockSkew)))
 		},
 	}
 
@@ -74,16 +106,15 @@ var (
 		Short: "Check the Submariner deployment",
 		Long:  "This command checks that the Submariner components are properly deployed and running with no overlapping CIDRs.",
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				diagnoseRestConfigProducer.RunOnAllContexts(func(clusterInfo *cluster.Info, ns string, status reporter.Interface) error {
-					if clusterInfo.Submariner == nil && clusterInfo.ServiceDiscovery == nil {
-						status.Warning(constants.SubmarinerNotInstalled)
+			exit.OnError(runDiagnose(func(clusterInfo *cluster.Info, ns string, status reporter.Interface) error {
+				if clusterInfo.Submariner == nil && clusterInfo.ServiceDiscovery == nil {
+					status.Warning(constants.SubmarinerNotInstalled)
 
-						return nil
-					}
+					return nil
+				}
 
-					return diagnose.Deployments(clusterInfo, ns, status) //nolint:wrapcheck // No need to wrap error here.
-				}, cli.NewReporter()))
+				return diagnose.Deployments(clusterInfo, ns, status) //nolint:wrapcheck // No need to wrap error here.
+			}))
 		},
 	}
 
@@ -92,17 +123,16 @@ var (
 		Short: "Check the Kubernetes version",
 		Long:  "This command checks if Submariner can be deployed on the Kubernetes version.",
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(diagnoseRestConfigProducer.RunOnAllContexts(diagnose.K8sVersion, cli.NewReporter()))
+			exit.OnError(runDiagnose(diagnose.K8sVersion))
 		},
 	}
 
 	diagnoseKubeProxyModeCmd = &cobra.Command{
 		Use:   "kube-proxy-mode",
 		Short: "Check the kube-proxy mode",
-		Long:  "This command checks if the kube-proxy mode is supported by Submariner.",
+		Long:  "This command checks if the kube-proxy mode is supported by Submariner with the deployed cable driver.",
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				diagnoseRestConfigProducer.RunOnAllContexts(restconfig.IfConnectivityInstalled(diagnose.KubeProxyMode), cli.NewReporter()))
+			exit.OnError(runDiagnose(restconfig.IfConnectivityInstalled(diagnose.KubeProxyMode)))
 		},
 	}
 
@@ -118,8 +148,7 @@ var (
 		Long:  "This command checks if the firewall configuration allows traffic over vx-submariner interface.",
 		Args:  checkNoArguments,
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				diagnoseRestConfigProducer.RunOnAllContexts(restconfig.IfConnectivityInstalled(firewallIntraVxLANConfig), cli.NewReporter()))
+			exit.OnError(runDiagnose(restconfig.IfConnectivityInstalled(firewallIntraVxLANConfig)))
 		},
 	}
 
@@ -143,12 +172,28 @@ var (
 		},
 	}
 
+	diagnoseFirewallNATTCmd = &cobra.Command{
+		Use:   "natt --context <localcontext> --remotecontext <remotecontext>",
+		Short: "Check reachability of the NAT-T port between the Gateway nodes",
+		Long: "This command checks if the encapsulation (NAT-T) port of each Gateway node is reachable from the other cluster's " +
+			"Gateway node, on both its public and private IPs.",
+		Args: checkNoArguments,
+		Run: func(command *cobra.Command, args []string) {
+			runLocalRemoteCommand(diagnoseFirewallNATTRestConfigProducer, diagnose.NATTPortAcrossClusters)
+		},
+	}
+
 	diagnoseAllCmd = &cobra.Command{
 		Use:   "all",
 		Short: "Run all diagnostic checks (except those requiring two kubecontexts)",
 		Long:  "This command runs all diagnostic checks (except those requiring two kubecontexts) and reports any issues",
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(diagnoseAll(cli.NewReporter()))
+			err := runDiagnose(diagnoseAllOnCluster)
+
+			fmt.Fprintf(os.Stderr, "Skipping inter-cluster firewall check as it requires two kubeconfigs."+
+				" Please run \"subctl diagnose firewall inter-cluster\" command manually.\n")
+
+			exit.OnError(err)
 		},
 	}
 
@@ -157,9 +202,7 @@ var (
 		Short: "Check service discovery functionality",
 		Long:  "This command checks if service discovery is functioning properly.",
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				diagnoseRestConfigProducer.RunOnAllContexts(
-					restconfig.IfServiceDiscoveryInstalled(diagnose.ServiceDiscovery), cli.NewReporter()))
+			exit.OnError(runDiagnose(restconfig.IfServiceDiscoveryInstalled(diagnose.ServiceDiscovery)))
 		},
 	}
 )
@@ -175,13 +218,26 @@ func init() {
 func addDiagnoseSubCommands() {
 	addDiagnoseFWConfigFlags(diagnoseAllCmd)
 
+	for _, command := range []*cobra.Command{
+		diagnoseCNICmd, diagnoseCIDRsCmd, diagnoseConnectionsCmd, diagnoseDeploymentCmd, diagnoseVersionCmd, diagnoseKubeProxyModeCmd,
+		diagnoseMTUCmd, diagnoseClockSkewCmd, diagnoseAllCmd, diagnoseServiceDiscoveryCmd, diagnoseFirewallVxLANCmd,
+	} {
+		command.Flags().StringVarP(&diagnoseOutput, "output", "o", "text",
+			"output format: text, or json for a report of each check printed at the end")
+	}
+
 	diagnoseCmd.AddCommand(diagnoseCNICmd)
+	diagnoseCmd.AddCommand(diagnoseCIDRsCmd)
 	diagnoseCmd.AddCommand(diagnoseConnectionsCmd)
 	diagnose.AddDeploymentImageOverrideFlag(diagnoseDeploymentCmd.Flags())
 	diagnoseCmd.AddCommand(diagnoseDeploymentCmd)
 	diagnoseCmd.AddCommand(diagnoseVersionCmd)
 	diagnose.AddKubeProxyImageOverrideFlag(diagnoseKubeProxyModeCmd.Flags())
 	diagnoseCmd.AddCommand(diagnoseKubeProxyModeCmd)
+	diagnose.AddFirewallImageOverrideFlag(diagnoseMTUCmd.Flags())
+	diagnoseCmd.AddCommand(diagnoseMTUCmd)
+	diagnose.AddFirewallImageOverrideFlag(diagnoseClockSkewCmd.Flags())
+	diagnoseCmd.AddCommand(diagnoseClockSkewCmd)
 	diagnoseCmd.AddCommand(diagnoseAllCmd)
 	diagnose.AddFirewallImageOverrideFlag(diagnoseFirewallCmd.Flags())
 	diagnoseCmd.AddCommand(diagnoseFirewallCmd)
@@ -194,10 +250,13 @@ func addDiagnoseFirewallSubCommands() {
 	addDiagnoseFWConfigFlags(diagnoseFirewallTunnelCmd)
 	diagnoseFirewallNatDiscoveryRestConfigProducer.SetupFlags(diagnoseFirewallNatDiscovery.Flags())
 	addDiagnoseFWConfigFlags(diagnoseFirewallNatDiscovery)
+	diagnoseFirewallNATTRestConfigProducer.SetupFlags(diagnoseFirewallNATTCmd.Flags())
+	addDiagnoseFWConfigFlags(diagnoseFirewallNATTCmd)
 
 	diagnoseFirewallCmd.AddCommand(diagnoseFirewallVxLANCmd)
 	diagnoseFirewallCmd.AddCommand(diagnoseFirewallTunnelCmd)
 	diagnoseFirewallCmd.AddCommand(diagnoseFirewallNatDiscovery)
+	diagnoseFirewallCmd.AddCommand(diagnoseFirewallNATTCmd)
 }
 
 func addDiagnoseFWConfigFlags(command *cobra.Command) {
@@ -217,33 +276,77 @@ var allDiagnoseCommands = []restconfig.PerContextFn{
 	diagnose.Deployments,
 	restconfig.IfConnectivityInstalled(
 		diagnose.CNIConfig,
+		diagnose.ClusterCIDRs,
 		diagnose.Connections,
 		diagnose.KubeProxyMode,
+		diagnose.NewMTUChecker().Check,
+		diagnose.ClockSkew,
 		firewallIntraVxLANConfig,
 		diagnose.GlobalnetConfig),
 	restconfig.IfServiceDiscoveryInstalled(diagnose.ServiceDiscovery),
 }
 
-func diagnoseAll(status reporter.Interface) error {
+func diagnoseAllOnCluster(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	diagnoseErrors := []error{}
+
+	for _, command := range allDiagnoseCommands {
+		diagnoseErrors = append(diagnoseErrors, command(clusterInfo, namespace, status))
+
+		fmt.Println()
+	}
+
+	return k8serrors.NewAggregate(diagnoseErrors)
+}
+
+// runDiagnose runs the given check on all the contexts. With JSON output, the outcome of each step is recorded and printed
+// as a JSON report at the end, and everything else is printed on stderr.
+func runDiagnose(function restconfig.PerContextFn) error {
+	if diagnoseOutput != "json" {
+		exit.OnErrorWithMessage(validateDiagnoseOutput(), "Invalid output format")
+
+		return diagnoseRestConfigProducer.RunOnAllContexts(function, cli.NewReporter()) //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	recorder := cli.NewRecorder(cli.NewReporter())
+
+	// The checks print some details directly, keep them out of the report
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
 	err := diagnoseRestConfigProducer.RunOnAllContexts(
 		func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
-			diagnoseErrors := []error{}
+			recorder.SetCluster(clusterInfo.Name)
+			return function(clusterInfo, namespace, status)
+		}, recorder.Reporter())
 
-			for _, command := range allDiagnoseCommands {
-				diagnoseErrors = append(diagnoseErrors, command(clusterInfo, namespace, status))
+	os.Stdout = stdout
 
-				fmt.Println()
-			}
+	report, marshalErr := json.MarshalIndent(struct {
+		Failed bool              `json:"failed"`
+		Checks []cli.CheckResult `json:"checks"`
+	}{
+		Failed: err != nil || recorder.Failed(),
+		Checks: recorder.Results(),
+	}, "", "  ")
+	exit.OnErrorWithMessage(marshalErr, "Error generating the report")
 
-			return k8serrors.NewAggregate(diagnoseErrors)
-		}, status)
+	fmt.Println(string(report))
 
-	fmt.Printf("Skipping inter-cluster firewall check as it requires two kubeconfigs." +
-		" Please run \"subctl diagnose firewall inter-cluster\" command manually.\n")
+	if err == nil && recorder.Failed() {
+		return errors.New("some checks failed")
+	}
 
 	return err //nolint:wrapcheck // No need to wrap errors here.
 }
 
+func validateDiagnoseOutput() error {
+	if diagnoseOutput != "text" && diagnoseOutput != "json" {
+		return fmt.Errorf("unsupported output format %q, use \"text\" or \"json\"", diagnoseOutput)
+	}
+
+	return nil
+}
+
 func runLocalRemoteCommand(localRemoteRestConfigProducer *restconfig.Producer,
 	function func(
 		localClusterInfo, remoteClusterInfo *cluster.Info, namespace string, options diagnose.FirewallOptions, status reporter.Interface,
@@ -23,6 +23,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/cli"
@@ -32,7 +33,10 @@ import (
 	"github.com/submariner-io/subctl/pkg/cluster"
 )
 
-var options gather.Options
+var (
+	options         gather.Options
+	gatherSinceTime string
+)
 
 var gatherRestConfigProducer = restconfig.NewProducer().WithContextsFlag()
 
@@ -40,8 +44,9 @@ var gatherCmd = &cobra.Command{
 	Use:   "gather",
 	Short: "Gather troubleshooting information from a cluster",
 	Long: fmt.Sprintf("This command gathers information from a submariner cluster for troubleshooting. The information gathered "+
-		"can be selected by component (%v) and type (%v). Default is to capture all data.",
-		strings.Join(gather.AllModules.Elements(), ","), strings.Join(gather.AllTypes.Elements(), ",")),
+		"can be selected by module (%v), component (%v) and type (%v). Default is to capture all data.",
+		strings.Join(gather.AllModules.Elements(), ","), strings.Join(gather.AllComponents.Elements(), ","),
+		strings.Join(gather.AllTypes.Elements(), ",")),
 	Run: func(command *cobra.Command, args []string) {
 		if options.Directory == "" {
 			options.Directory = "submariner-" + time.Now().UTC().Format("20060102150405") // submariner-YYYYMMDDHHMMSS
@@ -52,10 +57,25 @@ var gatherCmd = &cobra.Command{
 
 		status := cli.NewReporter()
 
-		exit.OnError(gatherRestConfigProducer.RunOnAllContexts(
-			func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
-				return gather.Data(clusterInfo, status, options) //nolint:wrapcheck // No need to wrap errors here.
-			}, status))
+		found, err := gatherRestConfigProducer.RunOnSelectedContexts(
+			func(clusterInfos []*cluster.Info, _ []string, status reporter.Interface) error {
+				return gather.AllClusters(clusterInfos, status, options) //nolint:wrapcheck // No need to wrap errors here.
+			}, status)
+		if !found {
+			err = gatherRestConfigProducer.RunOnAllContexts(
+				func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+					return gather.Data(clusterInfo, status, options) //nolint:wrapcheck // No need to wrap errors here.
+				}, status)
+		}
+
+		exit.OnError(err)
+
+		if options.Redact {
+			archive, err := gather.Archive(options.Directory)
+			exit.OnErrorWithMessage(err, "Error creating the archive")
+
+			fmt.Printf("The redacted data is archived in %q\n", archive)
+		}
 	},
 }
 
@@ -69,11 +89,22 @@ func addGatherFlags(gatherCmd *cobra.Command) {
 		"comma-separated list of data types to gather")
 	gatherCmd.Flags().StringSliceVar(&options.Modules, "module", gather.AllModules.Elements(),
 		"comma-separated list of components for which to gather data")
+	gatherCmd.Flags().StringSliceVar(&options.Components, "components", gather.AllComponents.Elements(),
+		"comma-separated list of components for which to gather data, within the selected modules")
 	gatherCmd.Flags().StringVar(&options.Directory, "dir", "",
 		"the directory in which to store files. If not specified, a directory of the form \"submariner-<timestamp>\" "+
 			"is created in the current directory")
+	gatherCmd.Flags().DurationVar(&options.Since, "since", 0,
+		"only gather logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs")
+	gatherCmd.Flags().StringVar(&gatherSinceTime, "since-time", "",
+		"only gather logs after a specific date (RFC3339). Defaults to all logs. Only one of since-time / since may be used")
+	gatherCmd.Flags().IntVar(&options.MaxConcurrency, "max-concurrency", 4,
+		"the maximum number of clusters to gather data from concurrently, when several contexts are specified")
 	gatherCmd.Flags().BoolVar(&options.IncludeSensitiveData, "include-sensitive-data", false,
 		"do not redact sensitive data such as credentials and security tokens")
+	gatherCmd.Flags().BoolVar(&options.Redact, "redact", false,
+		"redact sensitive fields such as the IPsec PSK and broker credentials from the gathered resources, "+
+			"and archive the result in a single file suitable for sharing")
 	gatherRestConfigProducer.SetupFlags(gatherCmd.Flags())
 }
 
@@ -90,5 +121,36 @@ func checkGatherArguments() error {
 		}
 	}
 
+	if options.Redact && options.IncludeSensitiveData {
+		return errors.New("at most one of redact / include-sensitive-data may be specified")
+	}
+
+	for _, c := range options.Components {
+		if !gather.AllComponents.Contains(c) {
+			return fmt.Errorf("%q is not a supported component", c)
+		}
+	}
+
+	if options.MaxConcurrency < 1 {
+		return fmt.Errorf("the maximum concurrency %d must be at least 1", options.MaxConcurrency)
+	}
+
+	if options.Since < 0 {
+		return fmt.Errorf("the since duration %v must not be negative", options.Since)
+	}
+
+	if gatherSinceTime != "" {
+		if options.Since != 0 {
+			return errors.New("at most one of since-time / since may be specified")
+		}
+
+		sinceTime, err := time.Parse(time.RFC3339, gatherSinceTime)
+		if err != nil {
+			return fmt.Errorf("the since time %q is not a valid RFC3339 time: %w", gatherSinceTime, err)
+		}
+
+		options.SinceTime = sinceTime
+	}
+
 	return nil
 }
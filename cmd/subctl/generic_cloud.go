@@ -19,6 +19,10 @@ limitations under the License.
 package subctl
 
 import (
+	"context"
+	"os"
+	"os/signal"
+
 	"github.com/spf13/cobra"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/cli"
@@ -30,7 +34,9 @@ import (
 
 var (
 	genericCloudConfig struct {
-		gateways int
+		reportOnly bool
+		gateways   int
+		nodeFilter string
 	}
 
 	genericPrepareCmd = &cobra.Command{
@@ -41,7 +47,7 @@ var (
 			exit.OnError(cloudRestConfigProducer.RunOnSelectedContext(
 				func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
 					return prepare.GenericCluster( //nolint:wrapcheck // No need to wrap errors here.
-						clusterInfo, genericCloudConfig.gateways, status)
+						clusterInfo, genericCloudConfig.gateways, genericCloudConfig.nodeFilter, status)
 				}, cli.NewReporter()))
 		},
 	}
@@ -51,9 +57,14 @@ var (
 		Short: "Cleans up a cluster after Submariner uninstallation",
 		Long:  "This command removes the labels from gateway nodes after Submariner uninstallation.",
 		Run: func(cmd *cobra.Command, args []string) {
+			// Interrupting the cleanup stops it cleanly, with a report of what was done
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
 			exit.OnError(cloudRestConfigProducer.RunOnSelectedContext(
 				func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
-					return cleanup.GenericCluster(clusterInfo, status) //nolint:wrapcheck // No need to wrap errors here.
+					return cleanup.GenericCluster( //nolint:wrapcheck // No need to wrap errors here.
+						ctx, clusterInfo, genericCloudConfig.reportOnly, status)
 				}, cli.NewReporter()))
 		},
 	}
@@ -61,7 +72,11 @@ var (
 
 func init() {
 	genericPrepareCmd.Flags().IntVar(&genericCloudConfig.gateways, "gateways", defaultNumGateways, "Number of gateways to deploy")
+	genericPrepareCmd.Flags().StringVar(&genericCloudConfig.nodeFilter, "gateway-node-filter", "",
+		"label selector restricting the nodes which can be labeled as gateways, e.g. topology.kubernetes.io/zone=us-east-1a")
 	cloudPrepareCmd.AddCommand(genericPrepareCmd)
 
+	genericCleanupCmd.Flags().BoolVar(&genericCloudConfig.reportOnly, "report-only", false,
+		"only report the gateway nodes which would be cleaned up, without changing them")
 	cloudCleanupCmd.AddCommand(genericCleanupCmd)
 }
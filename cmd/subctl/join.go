@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
@@ -40,8 +41,11 @@ import (
 )
 
 var (
-	joinFlags    join.Options
-	labelGateway bool
+	joinFlags        join.Options
+	labelGateway     bool
+	brokerKubeConfig string
+	brokerContext    string
+	joinIPSecPSKFrom string
 )
 
 var joinRestConfigProducer = restconfig.NewProducer()
@@ -52,11 +56,20 @@ var joinCmd = &cobra.Command{
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		status := cli.NewReporter()
-		checkArgumentPassed(args)
 
-		brokerInfo, err := broker.ReadInfoFromFile(args[0])
-		exit.OnError(status.Error(err, "Error loading the broker information from the given file"))
-		status.Success("%s indicates broker is at %s", args[0], brokerInfo.BrokerURL)
+		var brokerInfo *broker.Info
+
+		if brokerKubeConfig != "" {
+			brokerInfo = brokerInfoFromKubeConfig(status)
+		} else {
+			checkArgumentPassed(args)
+
+			var err error
+
+			brokerInfo, err = broker.ReadInfoFromFile(args[0])
+			exit.OnError(status.Error(err, "Error loading the broker information from the given file"))
+			status.Success("%s indicates broker is at %s", args[0], brokerInfo.BrokerURL)
+		}
 
 		exit.OnError(joinRestConfigProducer.RunOnSelectedContext(
 			func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
@@ -71,6 +84,12 @@ func addLoadBalancerFlag(cmd *cobra.Command, p *bool) {
 
 func init() {
 	addJoinFlags(joinCmd)
+	joinCmd.Flags().StringVar(&brokerKubeConfig, "broker-kubeconfig", "",
+		"kubeconfig of the broker cluster, used to retrieve the broker information instead of a broker-info.subm file")
+	joinCmd.Flags().StringVar(&brokerContext, "broker-context", "",
+		"context to use in the broker kubeconfig (the current context if unspecified)")
+	joinCmd.Flags().StringVar(&joinIPSecPSKFrom, "ipsec-psk-from", "",
+		"import the IPsec PSK from an existing broker-info.subm file; required with --broker-kubeconfig")
 	joinRestConfigProducer.SetupFlags(joinCmd.Flags())
 	rootCmd.AddCommand(joinCmd)
 }
@@ -106,7 +125,10 @@ func addJoinFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSliceVar(&joinFlags.CustomDomains, "custom-domains", nil,
 		"list of domains to use for multicluster service discovery")
 	cmd.Flags().StringSliceVar(&joinFlags.ImageOverrideArr, "image-override", nil,
-		"override component image")
+		fmt.Sprintf("override component image (component=repository/image:tag, can be repeated), the image version applies "+
+			"to the other components; valid components are %s", strings.Join(cluster.ImageOverrideComponents(), ", ")))
+	cmd.Flags().StringVar(&joinFlags.ImageManifest, "image-manifest", "",
+		"file mapping components to images pinned by digest (image@sha256:...), for air-gapped deployments")
 	cmd.Flags().BoolVar(&joinFlags.HealthCheckEnabled, "health-check", true,
 		"enable Gateway health check")
 	cmd.Flags().Uint64Var(&joinFlags.HealthCheckInterval, "health-check-interval", 1,
@@ -122,6 +144,55 @@ func addJoinFlags(cmd *cobra.Command) {
 
 	cmd.Flags().BoolVar(&joinFlags.BrokerK8sSecure, "check-broker-certificate", true,
 		"check the broker certificate (disable this to allow \"insecure\" connections)")
+	cmd.Flags().StringVar(&joinFlags.BrokerCAFile, "broker-ca-file", "",
+		"PEM-encoded CA bundle to verify the broker certificate against, e.g. for a self-signed certificate, "+
+			"instead of disabling the check")
+
+	cmd.Flags().DurationVar(&joinFlags.BrokerTokenTTL, "broker-token-ttl", 0,
+		"use a bound broker token expiring after the given duration instead of a long-lived token; the token isn't "+
+			"refreshed, broker connectivity stops when it expires unless the cluster is joined again")
+
+	cmd.Flags().DurationVar(&joinFlags.RetryBudget, "retry-for", 0,
+		"keep retrying the Submariner deployment for the given duration while the cluster is unavailable")
+
+	cmd.Flags().BoolVar(&joinFlags.VerifyKernelModules, "check-kernel-modules", false,
+		"check that the kernel modules required by the cable driver are available on the gateway node (requires creating a pod)")
+	cmd.Flags().BoolVar(&joinFlags.VerifyKubeProxyMode, "check-kube-proxy", false,
+		"warn when the kube-proxy mode or configuration is known to cause issues with Submariner")
+	cmd.Flags().BoolVar(&joinFlags.VerifyRepository, "check-repository", false,
+		"check that the image repository's registry is reachable before deploying")
+	cmd.Flags().BoolVar(&joinFlags.SkipVersionCompatCheck, "skip-version-check", false,
+		"skip checking that the Submariner version is compatible with the version deployed on the broker")
+	cmd.Flags().BoolVar(&joinFlags.OmitInlineBrokerFields, "omit-inline-broker-fields", false,
+		"omit the deprecated inline broker connection fields from the Submariner resource when the version no longer needs them")
+	cmd.Flags().IntVar(&joinFlags.MaxPreferredServers, "max-preferred-servers", 0,
+		"maximum number of clusters with the preferred server setting, checked when enabling it (0 means unlimited)")
+	cmd.Flags().BoolVar(&joinFlags.StrictPreferredServers, "strict-preferred-servers", false,
+		"fail instead of warning when enabling the preferred server setting exceeds the maximum")
+	cmd.Flags().IntVar(&joinFlags.GatewayCount, "gateways", 0,
+		"number of gateway nodes expected for HA; a warning is shown when fewer nodes are labeled as gateways (0 to skip the check)")
+	cmd.Flags().BoolVar(&joinFlags.StrictHealthCheck, "strict-health-check", false,
+		"fail instead of warning when the health check settings would take too long to detect an unhealthy connection")
+	cmd.Flags().BoolVar(&joinFlags.DryRun, "dry-run", false,
+		"render the PSK secret and Submariner resource which would be deployed as YAML on stdout, without applying anything")
+	cmd.Flags().BoolVar(&joinFlags.VerifyReferencedObjects, "verify-references", false,
+		"check that the secrets and config maps referenced by the Submariner resource exist before applying it")
+	cmd.Flags().BoolVar(&joinFlags.WaitForReady, "wait", false,
+		"wait for the gateways, route agents and other deployed components to be ready")
+	cmd.Flags().DurationVar(&joinFlags.ReadyWaitTimeout, "wait-timeout", 5*time.Minute,
+		"maximum time to wait for the components to be ready, with --wait")
+	cmd.Flags().StringVar(&joinFlags.BrokerHTTPProxy, "broker-http-proxy", "",
+		"proxy URL for HTTP connections from subctl to the broker, instead of the environment's proxy settings")
+	cmd.Flags().StringVar(&joinFlags.BrokerHTTPSProxy, "broker-https-proxy", "",
+		"proxy URL for HTTPS connections from subctl to the broker, instead of the environment's proxy settings")
+	cmd.Flags().StringToStringVar(&joinFlags.SecretLabels, "secret-labels", nil,
+		"labels to add to the broker and IPsec PSK secrets, as key=value pairs")
+	cmd.Flags().StringToStringVar(&joinFlags.SecretAnnotations, "secret-annotations", nil,
+		"annotations to add to the broker and IPsec PSK secrets, as key=value pairs")
+	cmd.Flags().StringVar(&joinFlags.SecretNameSuffix, "secret-name-suffix", "",
+		"suffix appended to the names of the broker and IPsec PSK secrets, to keep several Submariner instances apart")
+	cmd.Flags().StringVar(&joinFlags.ExistingPSKSecret, "ipsec-psk-secret", "",
+		"name of an existing secret, with a \"psk\" key, holding the IPsec PSK; subctl uses it instead of creating the PSK secret")
 }
 
 func joinInContext(brokerInfo *broker.Info, clusterInfo *cluster.Info, status reporter.Interface) error {
@@ -211,6 +282,27 @@ func askForGatewayNode(workerNodeNames []string) (string, error) {
 	return answers.Node, nil
 }
 
+func brokerInfoFromKubeConfig(status reporter.Interface) *broker.Info {
+	if joinIPSecPSKFrom == "" && joinFlags.ExistingPSKSecret == "" {
+		exit.WithMessage("The IPsec PSK isn't stored on the broker cluster, specify the broker-info.subm file to import it " +
+			"from with --ipsec-psk-from, or reference an existing PSK secret with --ipsec-psk-secret")
+	}
+
+	brokerInfo, err := broker.InfoFromKubeConfig(context.TODO(), brokerKubeConfig, brokerContext)
+	exit.OnError(status.Error(err, "Error retrieving the broker information from the broker kubeconfig"))
+
+	if joinIPSecPSKFrom != "" {
+		pskInfo, err := broker.ReadInfoFromFile(joinIPSecPSKFrom)
+		exit.OnError(status.Error(err, "Error importing the IPsec PSK from the given file"))
+
+		brokerInfo.IPSecPSK = pskInfo.IPSecPSK
+	}
+
+	status.Success("The broker kubeconfig indicates broker is at %s", brokerInfo.BrokerURL)
+
+	return brokerInfo
+}
+
 func checkArgumentPassed(args []string) {
 	if len(args) == 0 {
 		exit.WithMessage("The broker-info.subm file argument generated by 'subctl deploy-broker' is missing")
@@ -0,0 +1,97 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subctl
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/exit"
+	"github.com/submariner-io/subctl/internal/restconfig"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/secret"
+)
+
+var (
+	rotatePSKBrokerInfoFile string
+
+	rotatePSKRestConfigProducer = restconfig.NewProducer().WithContextsFlag()
+
+	rotatePSKCmd = &cobra.Command{
+		Use:   "rotate-psk",
+		Short: "Rotate the IPsec PSK",
+		Long: "This command generates a new IPsec PSK and applies it to all the selected clusters, restarting their gateways " +
+			"so that the tunnels are re-established. All the clusters connected through the same broker must be rotated together. " +
+			"The broker information file is updated so that clusters joined later use the new PSK.",
+		Run: func(cmd *cobra.Command, args []string) {
+			status := cli.NewReporter()
+
+			found, err := rotatePSKRestConfigProducer.RunOnSelectedContexts(rotatePSK, status)
+			if !found {
+				err = rotatePSKRestConfigProducer.RunOnSelectedContext(
+					func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+						return rotatePSK([]*cluster.Info{clusterInfo}, []string{namespace}, status)
+					}, status)
+			}
+
+			exit.OnError(err)
+		},
+	}
+)
+
+func init() {
+	rotatePSKCmd.Flags().StringVar(&rotatePSKBrokerInfoFile, "broker-info", broker.InfoFileName,
+		"the broker information file to update with the new PSK")
+	rotatePSKRestConfigProducer.SetupFlags(rotatePSKCmd.Flags())
+	rootCmd.AddCommand(rotatePSKCmd)
+}
+
+func rotatePSK(clusterInfos []*cluster.Info, _ []string, status reporter.Interface) error {
+	psk, err := secret.RotateIPSecPSK(context.TODO(), clusterInfos, status)
+	if psk == nil {
+		return err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	if err != nil {
+		status.Warning("The broker information file %q wasn't updated since the rotation failed in some clusters, "+
+			"run the rotation again on all the clusters", rotatePSKBrokerInfoFile)
+
+		return err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	if _, statErr := os.Stat(rotatePSKBrokerInfoFile); statErr != nil {
+		status.Warning("The broker information file %q could not be read, clusters joined later will not use the new PSK",
+			rotatePSKBrokerInfoFile)
+
+		return nil
+	}
+
+	status.Start("Saving the new PSK to the broker information file %q", rotatePSKBrokerInfoFile)
+	defer status.End()
+
+	if fileErr := broker.UpdateIPSecPSKInFile(rotatePSKBrokerInfoFile, psk); fileErr != nil {
+		return status.Error(fileErr, "Error updating the broker information file")
+	}
+
+	return nil
+}
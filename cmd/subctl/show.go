@@ -19,6 +19,8 @@ limitations under the License.
 package subctl
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
 	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/internal/exit"
@@ -28,6 +30,7 @@ import (
 
 var (
 	showRestConfigProducer = restconfig.NewProducer().WithContextsFlag()
+	showOutput             string
 
 	// showCmd represents the show command.
 	showCmd = &cobra.Command{
@@ -40,8 +43,9 @@ var (
 		Short: "Show cluster connectivity information",
 		Long:  `This command shows information about Submariner endpoint connections with other clusters.`,
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				showRestConfigProducer.RunOnAllContexts(restconfig.IfConnectivityInstalled(show.Connections), cli.NewReporter()))
+			showInOutput(func(output *show.Output) restconfig.PerContextFn {
+				return restconfig.IfConnectivityInstalled(show.ConnectionsIn(output))
+			})
 		},
 	}
 	endpointsCmd = &cobra.Command{
@@ -49,8 +53,9 @@ var (
 		Short: "Show Submariner endpoint information",
 		Long:  `This command shows information about Submariner endpoints in a cluster.`,
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				showRestConfigProducer.RunOnAllContexts(restconfig.IfConnectivityInstalled(show.Endpoints), cli.NewReporter()))
+			showInOutput(func(output *show.Output) restconfig.PerContextFn {
+				return restconfig.IfConnectivityInstalled(show.EndpointsIn(output))
+			})
 		},
 	}
 	gatewaysCmd = &cobra.Command{
@@ -58,8 +63,9 @@ var (
 		Short: "Show Submariner gateway summary information",
 		Long:  `This command shows summary information about the Submariner gateways in a cluster.`,
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				showRestConfigProducer.RunOnAllContexts(restconfig.IfConnectivityInstalled(show.Gateways), cli.NewReporter()))
+			showInOutput(func(output *show.Output) restconfig.PerContextFn {
+				return restconfig.IfConnectivityInstalled(show.GatewaysIn(output))
+			})
 		},
 	}
 	networksCmd = &cobra.Command{
@@ -67,8 +73,7 @@ var (
 		Short: "Get information on your cluster related to Submariner",
 		Long:  `This command shows the status of Submariner in your cluster, and the relevant network details from your cluster.`,
 		Run: func(command *cobra.Command, args []string) {
-			exit.OnError(
-				showRestConfigProducer.RunOnAllContexts(show.Network, cli.NewReporter()))
+			showInOutput(show.NetworkIn)
 		},
 	}
 	versionCmd = &cobra.Command{
@@ -104,6 +109,11 @@ var (
 func init() {
 	showRestConfigProducer.SetupFlags(showCmd.PersistentFlags())
 	rootCmd.AddCommand(showCmd)
+
+	for _, cmd := range []*cobra.Command{connectionsCmd, endpointsCmd, gatewaysCmd, networksCmd} {
+		cmd.Flags().StringVarP(&showOutput, "output", "o", string(show.FormatTable), "output format: table, json or yaml")
+	}
+
 	showCmd.AddCommand(connectionsCmd)
 	showCmd.AddCommand(endpointsCmd)
 	showCmd.AddCommand(gatewaysCmd)
@@ -112,3 +122,19 @@ func init() {
 	showCmd.AddCommand(brokersCmd)
 	showCmd.AddCommand(allCmd)
 }
+
+func showInOutput(function func(output *show.Output) restconfig.PerContextFn) {
+	format, err := show.ParseFormat(showOutput)
+	exit.OnErrorWithMessage(err, "Invalid output format")
+
+	output := show.NewOutput(format)
+	if output.Structured() {
+		// Standard output only carries the structured document
+		showRestConfigProducer.WithHeadersTo(os.Stderr)
+	}
+
+	err = showRestConfigProducer.RunOnAllContexts(function(output), cli.NewReporter())
+
+	exit.OnError(output.Print())
+	exit.OnError(err)
+}
@@ -20,6 +20,7 @@ package subctl
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
@@ -33,7 +34,9 @@ import (
 )
 
 var uninstallOptions struct {
-	noPrompt bool
+	uninstall.Options
+	noPrompt  bool
+	removeAll bool
 }
 
 var uninstallRestConfigProducer = restconfig.NewProducer().WithDefaultNamespace(constants.OperatorNamespace)
@@ -49,26 +52,49 @@ var uninstallCmd = &cobra.Command{
 
 func init() {
 	uninstallCmd.Flags().BoolVarP(&uninstallOptions.noPrompt, "yes", "y", false, "automatically answer yes to confirmation prompt")
+	uninstallCmd.Flags().BoolVar(&uninstallOptions.RemoveNamespace, "remove-namespace", false,
+		"wait for the Submariner namespace to be removed, refusing if it contains other workloads")
+	uninstallCmd.Flags().BoolVar(&uninstallOptions.RemoveCRDs, "remove-crds", false,
+		"also remove the Multicluster Services API custom resource definitions, which may be shared with other implementations")
+	uninstallCmd.Flags().BoolVar(&uninstallOptions.removeAll, "all", false,
+		"remove everything installed by Submariner, including all custom resource definitions and the Submariner namespace; "+
+			"equivalent to --remove-crds --remove-namespace")
+	uninstallCmd.Flags().BoolVar(&uninstallOptions.Force, "force", false,
+		"remove the finalizers still blocking the deletion of the Submariner resources once the deletion times out")
+	uninstallCmd.Flags().DurationVar(&uninstallOptions.BrokerRetryBudget, "broker-retry-budget", time.Minute,
+		"how long to keep retrying broker operations which fail with transient errors (0 to disable retries)")
 	uninstallRestConfigProducer.SetupFlags(uninstallCmd.Flags())
 	rootCmd.AddCommand(uninstallCmd)
 }
 
 func uninstallInContext(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	if uninstallOptions.removeAll {
+		uninstallOptions.RemoveCRDs = true
+		uninstallOptions.RemoveNamespace = true
+	}
+
 	if !uninstallOptions.noPrompt {
-		result := false
-		prompt := &survey.Confirm{
-			Message: fmt.Sprintf(
-				"This will completely uninstall Submariner from the cluster %q. Are you sure you want to continue?",
-				clusterInfo.Name),
+		if !confirmUninstall(fmt.Sprintf(
+			"This will completely uninstall Submariner from the cluster %q. Are you sure you want to continue?",
+			clusterInfo.Name)) {
+			return nil
 		}
 
-		_ = survey.AskOne(prompt, &result)
-
-		if !result {
+		if uninstallOptions.RemoveCRDs && !confirmUninstall(fmt.Sprintf(
+			"This will also delete the Submariner and Multicluster Services API custom resource definitions on the cluster %q, "+
+				"along with all the resources of those types, including any created by other tools. Are you sure you want to continue?",
+			clusterInfo.Name)) {
 			return nil
 		}
 	}
 
 	return uninstall.All( //nolint:wrapcheck // No need to wrap errors here.
-		clusterInfo.ClientProducer, clusterInfo.Name, namespace, status)
+		clusterInfo.ClientProducer, clusterInfo.Name, namespace, uninstallOptions.Options, status)
+}
+
+func confirmUninstall(message string) bool {
+	result := false
+	_ = survey.AskOne(&survey.Confirm{Message: message}, &result)
+
+	return result
 }
@@ -0,0 +1,142 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+)
+
+const (
+	CheckPassed  = "pass"
+	CheckWarning = "warn"
+	CheckFailed  = "fail"
+)
+
+// CheckResult is the recorded outcome of an operation, from its start to its end.
+type CheckResult struct {
+	Cluster  string   `json:"cluster,omitempty"`
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Messages []string `json:"messages,omitempty"`
+}
+
+// Recorder is a reporter which records the outcome of each operation, while forwarding everything to another reporter.
+// Messages reported outside an operation are recorded as operations of their own.
+type Recorder struct {
+	delegate reporter.Basic
+	cluster  string
+	current  *CheckResult
+	results  []*CheckResult
+}
+
+// NewRecorder returns a recorder forwarding to the given reporter.
+func NewRecorder(delegate reporter.Basic) *Recorder {
+	return &Recorder{delegate: delegate}
+}
+
+// Reporter returns the reporter interface for the recorder.
+func (r *Recorder) Reporter() reporter.Interface {
+	return &reporter.Adapter{Basic: r}
+}
+
+// SetCluster sets the cluster attributed to the operations recorded from now on.
+func (r *Recorder) SetCluster(cluster string) {
+	r.End()
+	r.cluster = cluster
+}
+
+// Results returns the recorded results, in order.
+func (r *Recorder) Results() []CheckResult {
+	results := make([]CheckResult, len(r.results))
+	for i := range r.results {
+		results[i] = *r.results[i]
+	}
+
+	return results
+}
+
+// Failed returns true if any of the recorded operations failed.
+func (r *Recorder) Failed() bool {
+	for _, result := range r.results {
+		if result.Status == CheckFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Recorder) Start(message string, args ...interface{}) {
+	r.End()
+	r.current = r.newResult(fmt.Sprintf(message, args...))
+	r.delegate.Start(message, args...)
+}
+
+func (r *Recorder) Success(message string, args ...interface{}) {
+	r.record(CheckPassed, message, args...)
+	r.delegate.Success(message, args...)
+}
+
+func (r *Recorder) Failure(message string, args ...interface{}) {
+	r.record(CheckFailed, message, args...)
+	r.delegate.Failure(message, args...)
+}
+
+func (r *Recorder) Warning(message string, args ...interface{}) {
+	r.record(CheckWarning, message, args...)
+	r.delegate.Warning(message, args...)
+}
+
+func (r *Recorder) End() {
+	r.current = nil
+	r.delegate.End()
+}
+
+func (r *Recorder) newResult(name string) *CheckResult {
+	result := &CheckResult{
+		Cluster: r.cluster,
+		Name:    name,
+		Status:  CheckPassed,
+	}
+	r.results = append(r.results, result)
+
+	return result
+}
+
+func (r *Recorder) record(status, message string, args ...interface{}) {
+	if message == "" {
+		return
+	}
+
+	message = fmt.Sprintf(message, args...)
+
+	result := r.current
+	if result == nil {
+		result = r.newResult(message)
+	}
+
+	result.Messages = append(result.Messages, message)
+
+	// A failure overrides a warning, which overrides a success
+	if status == CheckFailed || (status == CheckWarning && result.Status == CheckPassed) {
+		result.Status = status
+	}
+}
@@ -0,0 +1,99 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/cli"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		recorder *cli.Recorder
+		status   reporter.Interface
+	)
+
+	BeforeEach(func() {
+		recorder = cli.NewRecorder(reporter.Silent())
+		status = recorder.Reporter()
+	})
+
+	DescribeTable("recording the outcome of an operation",
+		func(report func(), expectedStatus string, expectedMessages ...string) {
+			status.Start("Checking %s", "something")
+			report()
+			status.End()
+
+			results := recorder.Results()
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Name).To(Equal("Checking something"))
+			Expect(results[0].Status).To(Equal(expectedStatus))
+			Expect(results[0].Messages).To(HaveLen(len(expectedMessages)))
+
+			for i, message := range expectedMessages {
+				Expect(results[0].Messages[i]).To(Equal(message))
+			}
+			Expect(recorder.Failed()).To(Equal(expectedStatus == cli.CheckFailed))
+		},
+		Entry("no messages", func() {}, cli.CheckPassed),
+		Entry("a success", func() {
+			status.Success("All %d good", 2)
+		}, cli.CheckPassed, "All 2 good"),
+		Entry("a warning after a success", func() {
+			status.Success("Good")
+			status.Warning("Not so good")
+		}, cli.CheckWarning, "Good", "Not so good"),
+		Entry("a warning before a failure", func() {
+			status.Warning("Not so good")
+			status.Failure("Bad")
+			status.Success("Good")
+		}, cli.CheckFailed, "Not so good", "Bad", "Good"),
+		Entry("an error", func() {
+			_ = status.Error(errors.New("boom"), "Error checking")
+		}, cli.CheckFailed, "Error checking: boom"),
+	)
+
+	It("should record the messages reported outside an operation as operations of their own", func() {
+		status.Start("First")
+		status.End()
+		status.Warning("Outside")
+
+		Expect(recorder.Results()).To(Equal([]cli.CheckResult{
+			{Name: "First", Status: cli.CheckPassed},
+			{Name: "Outside", Status: cli.CheckWarning, Messages: []string{"Outside"}},
+		}))
+	})
+
+	It("should attribute the operations to the current cluster", func() {
+		recorder.SetCluster("east")
+		status.Start("First")
+		recorder.SetCluster("west")
+		status.Start("Second")
+		status.End()
+
+		Expect(recorder.Results()).To(Equal([]cli.CheckResult{
+			{Cluster: "east", Name: "First", Status: cli.CheckPassed},
+			{Cluster: "west", Name: "Second", Status: cli.CheckPassed},
+		}))
+	})
+})
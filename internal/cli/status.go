@@ -60,6 +60,16 @@ func NewReporter() reporter.Interface {
 		writer = NewSpinner(writer)
 	}
 
+	return newReporter(writer)
+}
+
+// NewReporterTo returns a reporter writing to the given writer, with no spinner or colors. This is useful to capture the
+// status of work running concurrently, to display it later.
+func NewReporterTo(writer io.Writer) reporter.Interface {
+	return newReporter(writer)
+}
+
+func newReporter(writer io.Writer) reporter.Interface {
 	s := &status{
 		logger:        NewLogger(writer, 0),
 		successFormat: " ✓ %s\n",
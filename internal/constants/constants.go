@@ -20,13 +20,16 @@ package constants
 
 // Arranged alphabetically.
 const (
-	DefaultBrokerNamespace       = "submariner-k8s-broker"
-	OperatorNamespace            = "submariner-operator"
-	SubmarinerBrokerAdminSA      = "submariner-k8s-broker-admin"
-	SubmarinerGatewayLabel       = "submariner.io/gateway"
-	SubmarinerNotInstalled       = "No Submariner feature is installed"
-	ConnectivityNotInstalled     = "Submariner connectivity feature is not installed"
-	ServiceDiscoveryNotInstalled = "Submariner service discovery feature is not installed"
-	TransientLabel               = "submariner.io/transient"
-	TrueLabel                    = "true"
+	DefaultBrokerNamespace            = "submariner-k8s-broker"
+	OperatorNamespace                 = "submariner-operator"
+	SubmarinerBrokerAdminSA           = "submariner-k8s-broker-admin"
+	SubmarinerGatewayLabel            = "submariner.io/gateway"
+	SubmarinerNotInstalled            = "No Submariner feature is installed"
+	SubctlVersionLabel                = "subctl.submariner.io/version"
+	SubmarinerVersionAnnotation       = "subctl.submariner.io/submariner-version"
+	SubctlDeployedByVersionAnnotation = "subctl.submariner.io/deployed-by-version"
+	ConnectivityNotInstalled          = "Submariner connectivity feature is not installed"
+	ServiceDiscoveryNotInstalled      = "Submariner service discovery feature is not installed"
+	TransientLabel                    = "submariner.io/transient"
+	TrueLabel                         = "true"
 )
@@ -0,0 +1,100 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Archive writes the contents of the given directory to a single gzipped tarball alongside it, and returns the tarball's
+// path. The paths in the tarball are relative to the directory's parent, so that it extracts to the same layout.
+func Archive(directory string) (string, error) {
+	directory = filepath.Clean(directory)
+	archivePath := directory + ".tar.gz"
+
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return "", errors.WithMessagef(err, "error creating archive %s", archivePath)
+	}
+
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err = filepath.Walk(directory, func(path string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		return addToArchive(tarWriter, filepath.Dir(directory), path, fileInfo)
+	})
+	if err != nil {
+		return "", errors.WithMessagef(err, "error archiving directory %s", directory)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return "", errors.WithMessagef(err, "error writing archive %s", archivePath)
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return "", errors.WithMessagef(err, "error writing archive %s", archivePath)
+	}
+
+	return archivePath, nil
+}
+
+func addToArchive(tarWriter *tar.Writer, baseDir, path string, fileInfo os.FileInfo) error {
+	header, err := tar.FileInfoHeader(fileInfo, "")
+	if err != nil {
+		return errors.WithMessagef(err, "error creating archive header for %s", path)
+	}
+
+	header.Name, err = filepath.Rel(baseDir, path)
+	if err != nil {
+		return errors.WithMessagef(err, "error determining the archive path of %s", path)
+	}
+
+	header.Name = filepath.ToSlash(header.Name)
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return errors.WithMessagef(err, "error writing archive header for %s", path)
+	}
+
+	if !fileInfo.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.WithMessagef(err, "error opening file %s", path)
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(tarWriter, file)
+
+	return errors.WithMessagef(err, "error archiving file %s", path)
+}
@@ -22,15 +22,18 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/admiral/pkg/stringset"
 	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/internal/component"
 	"github.com/submariner-io/subctl/internal/constants"
-	"github.com/submariner-io/subctl/internal/exit"
 	"github.com/submariner-io/subctl/internal/restconfig"
 	"github.com/submariner-io/subctl/pkg/brokercr"
 	"github.com/submariner-io/subctl/pkg/client"
@@ -39,15 +42,21 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
 	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Options struct {
+	SinceTime            time.Time
 	Directory            string
-	IncludeSensitiveData bool
 	Modules              []string
 	Types                []string
+	Components           []string
+	Since                time.Duration
+	MaxConcurrency       int
+	IncludeSensitiveData bool
+	Redact               bool
 }
 
 const (
@@ -55,8 +64,24 @@ const (
 	Resources = "resources"
 )
 
+const (
+	Gateway    = "gateway"
+	RouteAgent = "route-agent"
+)
+
 var AllModules = stringset.New(component.Connectivity, component.ServiceDiscovery, component.Broker, component.Operator)
 
+var AllComponents = stringset.New(Gateway, RouteAgent, component.Globalnet, component.ServiceDiscovery, component.Operator,
+	component.Broker)
+
+// The components covered by each module; a module is only gathered if at least one of its components is selected.
+var moduleComponents = map[string][]string{
+	component.Connectivity:     {Gateway, RouteAgent, component.Globalnet},
+	component.ServiceDiscovery: {component.ServiceDiscovery},
+	component.Broker:           {component.Broker},
+	component.Operator:         {component.Operator},
+}
+
 var AllTypes = stringset.New(Logs, Resources)
 
 var gatherFuncs = map[string]func(string, Info) bool{
@@ -66,6 +91,7 @@ var gatherFuncs = map[string]func(string, Info) bool{
 	component.Operator:         gatherOperator,
 }
 
+//nolint:gocritic // hugeParam: options - purposely passed by value.
 func Data(clusterInfo *cluster.Info, status reporter.Interface, options Options) error {
 	var warningsBuf bytes.Buffer
 
@@ -73,51 +99,184 @@ func Data(clusterInfo *cluster.Info, status reporter.Interface, options Options)
 		Deduplicate: true,
 	}))
 
+	err := gatherCluster(clusterInfo, os.Stdout, cli.NewReporter, options)
+	if err != nil {
+		return err
+	}
+
+	printWarnings(warningsBuf.String())
+
+	return nil
+}
+
+// AllClusters gathers data from the given clusters, from up to MaxConcurrency clusters at a time. The output for each
+// cluster is held back until the cluster is done, and its progress is reported on the given status. A failure on one
+// cluster doesn't prevent gathering data from the others. Each cluster has its own directory and summary, so the
+// concurrent clusters don't share any output.
+//
+//nolint:gocritic // hugeParam: options - purposely passed by value.
+func AllClusters(clusterInfos []*cluster.Info, status reporter.Interface, options Options) error {
+	if options.MaxConcurrency <= 1 {
+		clusterErrors := []error{}
+
+		for _, clusterInfo := range clusterInfos {
+			clusterErrors = append(clusterErrors, Data(clusterInfo, status, options))
+		}
+
+		return k8serrors.NewAggregate(clusterErrors)
+	}
+
+	var warningsBuf bytes.Buffer
+
+	rest.SetDefaultWarningHandler(rest.NewWarningWriter(&warningsBuf, rest.WarningWriterOptions{
+		Deduplicate: true,
+	}))
+
+	var (
+		waitGroup sync.WaitGroup
+		mutex     sync.Mutex
+		done      int
+	)
+
+	slots := make(chan struct{}, options.MaxConcurrency)
+	clusterErrors := make([]error, len(clusterInfos))
+
+	for i := range clusterInfos {
+		waitGroup.Add(1)
+
+		go func(i int) {
+			defer waitGroup.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			clusterName := clusterInfos[i].Name
+
+			mutex.Lock()
+			status.Success("Gathering information from cluster %q", clusterName)
+			mutex.Unlock()
+
+			output := &bytes.Buffer{}
+
+			clusterErrors[i] = gatherCluster(clusterInfos[i], output, func() reporter.Interface {
+				return cli.NewReporterTo(output)
+			}, options)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			done++
+
+			fmt.Print(output.String())
+
+			if clusterErrors[i] != nil {
+				status.Failure("Failed to gather information from cluster %q (%d of %d done): %v", clusterName, done,
+					len(clusterInfos), clusterErrors[i])
+			} else {
+				status.Success("Finished gathering information from cluster %q (%d of %d done)", clusterName, done, len(clusterInfos))
+			}
+		}(i)
+	}
+
+	waitGroup.Wait()
+
+	printWarnings(warningsBuf.String())
+
+	return k8serrors.NewAggregate(clusterErrors)
+}
+
+//nolint:gocritic // hugeParam: options - purposely passed by value.
+func gatherCluster(clusterInfo *cluster.Info, out io.Writer, newStatus func() reporter.Interface, options Options) error {
 	// concatenate the name of the cluster with the root gather directory
 	options.Directory = filepath.Join(options.Directory, clusterInfo.Name)
 
 	if _, err := os.Stat(options.Directory); os.IsNotExist(err) {
 		err := os.MkdirAll(options.Directory, 0o700)
 		if err != nil {
-			exit.OnErrorWithMessage(err, fmt.Sprintf("Error creating directory %q", options.Directory))
+			return errors.WithMessagef(err, "error creating directory %q", options.Directory)
 		}
 	}
 
-	gatherDataByCluster(clusterInfo, options)
+	gatherDataByCluster(clusterInfo, out, newStatus, options)
+
+	fmt.Fprintf(out, "Files are stored under directory %q\n", options.Directory)
 
-	fmt.Printf("Files are stored under directory %q\n", options.Directory)
+	return nil
+}
 
-	warnings := warningsBuf.String()
+func printWarnings(warnings string) {
 	if warnings != "" {
 		fmt.Printf("\nEncountered following Kubernetes warnings while running:\n%s", warnings)
 	}
-
-	return nil
 }
 
-func gatherDataByCluster(clusterInfo *cluster.Info, options Options) {
+//nolint:gocritic // hugeParam: options - purposely passed by value.
+func gatherDataByCluster(clusterInfo *cluster.Info, out io.Writer, newStatus func() reporter.Interface, options Options) {
 	clusterName := clusterInfo.Name
 
-	fmt.Printf("Gathering information from cluster %q\n", clusterName)
+	fmt.Fprintf(out, "Gathering information from cluster %q\n", clusterName)
 
 	info := Info{
 		Info:                 *clusterInfo,
 		ClusterName:          clusterName,
 		DirName:              options.Directory,
+		Components:           stringset.New(options.Components...),
+		LogOptions:           podLogOptions(&options),
 		IncludeSensitiveData: options.IncludeSensitiveData,
+		Redact:               options.Redact,
 		Summary:              &Summary{},
 	}
 
 	for _, module := range options.Modules {
+		if !hasSelectedComponent(module, info.Components) {
+			continue
+		}
+
 		for _, dataType := range options.Types {
-			info.Status = cli.NewReporter()
+			info.Status = newStatus()
 			info.Status.Start("Gathering %s %s", module, dataType)
 			gatherFuncs[module](dataType, info)
 			info.Status.End()
 		}
 	}
 
+	if info.Components.Contains(RouteAgent) && stringset.New(options.Types...).Contains(Resources) {
+		info.Status = newStatus()
+		info.Status.Start("Gathering network plugin details")
+		gatherNetworkPlugin(&info)
+		info.Status.End()
+	}
+
 	gatherClusterSummary(&info)
+
+	if options.Redact {
+		fmt.Fprintf(out, "Redacted %d sensitive fields in the resources gathered from cluster %q\n", info.Summary.RedactedFields,
+			clusterName)
+	}
+}
+
+func hasSelectedComponent(module string, components stringset.Interface) bool {
+	for _, c := range moduleComponents[module] {
+		if components.Contains(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// podLogOptions returns the log options restricting the gathered logs to the time window given in the options, if any.
+func podLogOptions(options *Options) corev1.PodLogOptions {
+	logOptions := corev1.PodLogOptions{}
+
+	if options.Since > 0 {
+		sinceSeconds := int64(options.Since.Seconds())
+		logOptions.SinceSeconds = &sinceSeconds
+	} else if !options.SinceTime.IsZero() {
+		logOptions.SinceTime = &metav1.Time{Time: options.SinceTime}
+	}
+
+	return logOptions
 }
 
 //nolint:gocritic // hugeParam: info - purposely passed by value.
@@ -129,21 +288,38 @@ func gatherConnectivity(dataType string, info Info) bool {
 
 	switch dataType {
 	case Logs:
-		gatherGatewayPodLogs(&info)
-		gatherRouteAgentPodLogs(&info)
-		gatherGlobalnetPodLogs(&info)
-		gatherNetworkPluginSyncerPodLogs(&info)
+		if info.Components.Contains(Gateway) {
+			gatherGatewayPodLogs(&info)
+		}
+
+		if info.Components.Contains(RouteAgent) {
+			gatherRouteAgentPodLogs(&info)
+			gatherNetworkPluginSyncerPodLogs(&info)
+		}
+
+		if info.Components.Contains(component.Globalnet) {
+			gatherGlobalnetPodLogs(&info)
+		}
+
 		gatherAddonPodLogs(&info)
 	case Resources:
-		gatherCNIResources(&info, info.Submariner.Status.NetworkPlugin)
-		gatherCableDriverResources(&info, info.Submariner.Spec.CableDriver)
-		gatherOVNResources(&info, info.Submariner.Status.NetworkPlugin)
-		gatherEndpoints(&info, info.Submariner.Spec.Namespace)
-		gatherClusters(&info, info.Submariner.Spec.Namespace)
-		gatherGateways(&info, info.Submariner.Spec.Namespace)
-		gatherClusterGlobalEgressIPs(&info)
-		gatherGlobalEgressIPs(&info)
-		gatherGlobalIngressIPs(&info)
+		if info.Components.Contains(Gateway) {
+			gatherCableDriverResources(&info, info.Submariner.Spec.CableDriver)
+			gatherEndpoints(&info, info.Submariner.Spec.Namespace)
+			gatherClusters(&info, info.Submariner.Spec.Namespace)
+			gatherGateways(&info, info.Submariner.Spec.Namespace)
+		}
+
+		if info.Components.Contains(RouteAgent) {
+			gatherCNIResources(&info, info.Submariner.Status.NetworkPlugin)
+			gatherOVNResources(&info, info.Submariner.Status.NetworkPlugin)
+		}
+
+		if info.Components.Contains(component.Globalnet) {
+			gatherClusterGlobalEgressIPs(&info)
+			gatherGlobalEgressIPs(&info)
+			gatherGlobalIngressIPs(&info)
+		}
 	default:
 		return false
 	}
@@ -180,7 +356,8 @@ func gatherDiscovery(dataType string, info Info) bool {
 func gatherBroker(dataType string, info Info) bool {
 	switch dataType {
 	case Resources:
-		brokerRestConfig, brokerNamespace, err := restconfig.ForBroker(info.Submariner, info.ServiceDiscovery)
+		brokerRestConfig, brokerNamespace, err := restconfig.ForBroker(context.TODO(), info.ClientProducer.ForKubernetes(), info.Submariner,
+			info.ServiceDiscovery)
 		if err != nil {
 			info.Status.Failure("Error getting the broker's rest config: %s", err)
 			return true
@@ -214,6 +391,11 @@ func gatherBroker(dataType string, info Info) bool {
 
 		info.ClusterName = "broker"
 
+		// The Broker resource can only be retrieved when running against the broker cluster itself
+		if brokerRestConfig == nil {
+			gatherBrokers(&info, constants.OperatorNamespace)
+		}
+
 		// The broker's ClusterRole used by member clusters only allows the below resources to be queried
 		gatherEndpoints(&info, brokerNamespace)
 		gatherClusters(&info, brokerNamespace)
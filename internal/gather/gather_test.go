@@ -0,0 +1,254 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/gather"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	kubeScheme "k8s.io/client-go/kubernetes/scheme"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testPSK         = "test-ipsec-psk"
+	testToken       = "test-broker-token"
+	testSDToken     = "test-service-discovery-token"
+	submarinersFile = "submariners_submariner-operator_submariner.yaml"
+)
+
+var _ = Describe("AllClusters", func() {
+	var (
+		directory string
+		options   gather.Options
+	)
+
+	newClusterInfo := func(name string, kubeObjects ...runtime.Object) *cluster.Info {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(kubeScheme.AddToScheme(scheme)).To(Succeed())
+
+		submariner := &operatorv1alpha1.Submariner{
+			ObjectMeta: metav1.ObjectMeta{Name: "submariner", Namespace: constants.OperatorNamespace},
+			Spec: operatorv1alpha1.SubmarinerSpec{
+				CeIPSecPSK:              testPSK,
+				BrokerK8sApiServerToken: testToken,
+			},
+		}
+
+		serviceDiscovery := &operatorv1alpha1.ServiceDiscovery{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-discovery", Namespace: constants.OperatorNamespace},
+			Spec:       operatorv1alpha1.ServiceDiscoverySpec{BrokerK8sApiServerToken: testSDToken},
+		}
+
+		return &cluster.Info{
+			Name:             name,
+			Submariner:       submariner,
+			ServiceDiscovery: serviceDiscovery,
+			ClientProducer: &client.DefaultProducer{
+				KubeClient:    fake.NewSimpleClientset(kubeObjects...),
+				DynamicClient: fakedynamic.NewSimpleDynamicClient(scheme, submariner, serviceDiscovery),
+				GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).Build(),
+			},
+		}
+	}
+
+	readFile := func(clusterName, fileName string) string {
+		data, err := os.ReadFile(filepath.Join(directory, clusterName, fileName))
+		Expect(err).To(Succeed())
+
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		directory = GinkgoT().TempDir()
+		options = gather.Options{
+			Directory:  directory,
+			Modules:    []string{"operator"},
+			Types:      []string{gather.Resources},
+			Components: []string{"operator"},
+		}
+	})
+
+	It("should gather the data of every cluster concurrently", func() {
+		options.MaxConcurrency = 2
+
+		Expect(gather.AllClusters([]*cluster.Info{newClusterInfo("east"), newClusterInfo("west")}, reporter.Silent(),
+			options)).To(Succeed())
+		Expect(filepath.Join(directory, "east", submarinersFile)).To(BeARegularFile())
+		Expect(filepath.Join(directory, "west", submarinersFile)).To(BeARegularFile())
+	})
+
+	DescribeTable("restricting the gathered components",
+		func(components []string, gathered bool) {
+			options.Components = components
+
+			Expect(gather.AllClusters([]*cluster.Info{newClusterInfo("east")}, reporter.Silent(), options)).To(Succeed())
+
+			if gathered {
+				Expect(filepath.Join(directory, "east", submarinersFile)).To(BeARegularFile())
+			} else {
+				Expect(filepath.Join(directory, "east", submarinersFile)).ToNot(BeAnExistingFile())
+			}
+		},
+		Entry("with the module's component", []string{"operator"}, true),
+		Entry("without the module's component", []string{"gateway", "broker"}, false),
+	)
+
+	DescribeTable("handling sensitive data",
+		func(includeSensitiveData, redact bool, expected, unexpected []string) {
+			options.IncludeSensitiveData = includeSensitiveData
+			options.Redact = redact
+			options.Modules = []string{"operator", "service-discovery"}
+			options.Components = []string{"operator"}
+
+			Expect(gather.AllClusters([]*cluster.Info{newClusterInfo("east")}, reporter.Silent(), options)).To(Succeed())
+
+			contents := readFile("east", submarinersFile) +
+				readFile("east", "servicediscoveries_submariner-operator_service-discovery.yaml")
+
+			for _, s := range expected {
+				Expect(contents).To(ContainSubstring(s))
+			}
+
+			for _, s := range unexpected {
+				Expect(contents).ToNot(ContainSubstring(s))
+			}
+		},
+		Entry("by default", false, false, []string{"##redacted-ipsec-psk##", "##redacted-token##"},
+			[]string{testPSK, testToken, testSDToken}),
+		Entry("when redacting", false, true, []string{"##redacted##"}, []string{testPSK, testToken, testSDToken}),
+		Entry("when including it", true, false, []string{testPSK, testToken, testSDToken}, []string{"##redacted"}),
+	)
+
+	DescribeTable("restricting the gathered logs",
+		func(since time.Duration, sinceTime time.Time, expectedFile string) {
+			labels := map[string]string{"name": "submariner-operator"}
+
+			options.Types = []string{gather.Logs}
+			options.Since = since
+			options.SinceTime = sinceTime
+
+			Expect(gather.AllClusters([]*cluster.Info{newClusterInfo("east",
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "submariner-operator", Namespace: constants.OperatorNamespace},
+					Spec: appsv1.DeploymentSpec{
+						Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+					},
+				},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name: "submariner-operator-abcde", Namespace: constants.OperatorNamespace, Labels: labels,
+				}},
+			)}, reporter.Silent(), options)).To(Succeed())
+
+			Expect(filepath.Join(directory, "east", expectedFile)).To(BeARegularFile())
+		},
+		Entry("without a time window", time.Duration(0), time.Time{}, "submariner-operator-abcde.log"),
+		Entry("with a duration", time.Hour, time.Time{}, "submariner-operator-abcde_since-1h0m0s.log"),
+		Entry("with a start time", time.Duration(0), time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			"submariner-operator-abcde_since-20230102030405.log"),
+	)
+
+	It("should gather the network plugin details", func() {
+		options.Modules = nil
+		options.Components = []string{gather.RouteAgent}
+
+		Expect(gather.AllClusters([]*cluster.Info{newClusterInfo("east",
+			&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "kube-flannel-ds", Namespace: "kube-flannel"},
+				Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "kube-flannel", Image: "docker.io/flannel/flannel:v0.21.2"}},
+					Volumes: []corev1.Volume{{Name: "flannel-cfg", VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "kube-flannel-cfg"}},
+					}}},
+				}}},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "kube-flannel-cfg", Namespace: "kube-flannel"},
+				Data:       map[string]string{"net-conf.json": `{"Backend": {"Type": "vxlan"}}`},
+			},
+		)}, reporter.Silent(), options)).To(Succeed())
+
+		report := readFile("east", "network-plugin.txt")
+		Expect(report).To(ContainSubstring("Network plugin: flannel\n"))
+		Expect(report).To(ContainSubstring("kube-flannel/kube-flannel-ds kube-flannel: docker.io/flannel/flannel:v0.21.2"))
+		Expect(report).To(ContainSubstring(`ConfigMap kube-flannel-cfg net-conf.json: {"Backend": {"Type": "vxlan"}}`))
+	})
+})
+
+var _ = Describe("Archive", func() {
+	It("should archive the directory with paths relative to its parent", func() {
+		directory := filepath.Join(GinkgoT().TempDir(), "submariner-gather")
+		Expect(os.MkdirAll(filepath.Join(directory, "east"), 0o700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(directory, "east", "summary.html"), []byte("summary"), 0o600)).To(Succeed())
+
+		archivePath, err := gather.Archive(directory + "/")
+		Expect(err).To(Succeed())
+		Expect(archivePath).To(Equal(directory + ".tar.gz"))
+
+		file, err := os.Open(archivePath)
+		Expect(err).To(Succeed())
+
+		defer file.Close()
+
+		gzipReader, err := gzip.NewReader(file)
+		Expect(err).To(Succeed())
+
+		tarReader := tar.NewReader(gzipReader)
+		contents := map[string]string{}
+
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+
+			Expect(err).To(Succeed())
+
+			data, err := io.ReadAll(tarReader)
+			Expect(err).To(Succeed())
+
+			contents[header.Name] = string(data)
+		}
+
+		Expect(contents).To(Equal(map[string]string{
+			"submariner-gather":                   "",
+			"submariner-gather/east":              "",
+			"submariner-gather/east/summary.html": "summary",
+		}))
+	})
+})
@@ -21,9 +21,11 @@ package gather
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -44,9 +46,9 @@ func gatherPodLogsByContainer(podLabelSelector, container string, info *Info) {
 
 		info.Status.Success("Found %d pods matching label selector %q", len(pods.Items), podLabelSelector)
 
-		podLogOptions := corev1.PodLogOptions{
-			Container: container,
-		}
+		podLogOptions := info.LogOptions
+		podLogOptions.Container = container
+
 		for i := range pods.Items {
 			info.Summary.PodLogs = append(info.Summary.PodLogs, outputPodLogs(&pods.Items[i], podLogOptions, info))
 		}
@@ -87,7 +89,21 @@ func writePodLogToFile(logStream io.ReadCloser, info *Info, podName, fileExtensi
 
 	logs = scrubSensitiveData(info, logs)
 
-	return writeLogToFile(logs, podName, info, fileExtension)
+	return writeLogToFile(logs, podName+logWindowSuffix(&info.LogOptions), info, fileExtension)
+}
+
+// logWindowSuffix returns the suffix identifying the time window the logs were restricted to, if any, so that it's
+// reflected in the log file names.
+func logWindowSuffix(podLogOptions *corev1.PodLogOptions) string {
+	if podLogOptions.SinceSeconds != nil {
+		return fmt.Sprintf("_since-%s", time.Duration(*podLogOptions.SinceSeconds)*time.Second)
+	}
+
+	if podLogOptions.SinceTime != nil {
+		return "_since-" + podLogOptions.SinceTime.UTC().Format("20060102150405")
+	}
+
+	return ""
 }
 
 func getLogFromStream(logStream io.ReadCloser) (string, error) {
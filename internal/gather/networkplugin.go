@@ -0,0 +1,240 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/network"
+	"github.com/submariner-io/submariner/pkg/cni"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const cilium = "cilium"
+
+// The DaemonSets deployed by each network plugin, whose images give the plugin's version.
+var networkPluginDaemonSets = map[string]string{
+	"calico-node":     cni.Calico,
+	"canal":           cni.CanalFlannel,
+	"cilium":          cilium,
+	"kindnet":         cni.KindNet,
+	"kube-flannel-ds": cni.Flannel,
+	"ovnkube-node":    cni.OVNKubernetes,
+	"sdn":             cni.OpenShiftSDN,
+	"weave-net":       cni.WeaveNet,
+}
+
+// The keys of the ConfigMaps mounted by each network plugin's DaemonSets which describe its encapsulation.
+var networkPluginConfigKeys = map[string][]string{
+	cni.CanalFlannel: {"net-conf.json"},
+	cilium:           {"tunnel", "tunnel-protocol", "routing-mode", "kube-proxy-replacement"},
+	cni.Flannel:      {"net-conf.json"},
+}
+
+var calicoIPPoolGVR = schema.GroupVersionResource{
+	Group:    "crd.projectcalico.org",
+	Version:  "v1",
+	Resource: "ippools",
+}
+
+// gatherNetworkPlugin writes the detected network plugin, its version and its encapsulation settings to
+// network-plugin.txt. Any ambiguity in the detection is recorded in the file rather than treated as a failure.
+func gatherNetworkPlugin(info *Info) {
+	report := &strings.Builder{}
+	notes := []string{}
+
+	daemonSets, err := info.ClientProducer.ForKubernetes().AppsV1().DaemonSets(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		notes = append(notes, fmt.Sprintf("Error listing the DaemonSets: %v", err))
+		daemonSets = &appsv1.DaemonSetList{}
+	}
+
+	pluginDaemonSets := map[string][]*appsv1.DaemonSet{}
+
+	for i := range daemonSets.Items {
+		if plugin, ok := networkPluginDaemonSets[daemonSets.Items[i].Name]; ok {
+			pluginDaemonSets[plugin] = append(pluginDaemonSets[plugin], &daemonSets.Items[i])
+		}
+	}
+
+	clusterNetwork, err := network.Discover(context.TODO(), info.ClientProducer.ForGeneral(), constants.OperatorNamespace)
+	if err != nil {
+		notes = append(notes, fmt.Sprintf("Error discovering the network: %v", err))
+	}
+
+	plugin, detectedVia, detectionNotes := detectNetworkPlugin(info, clusterNetwork, pluginDaemonSets)
+	notes = append(notes, detectionNotes...)
+
+	fmt.Fprintf(report, "Network plugin: %s\nDetected via: %s\n\nVersion:\n", plugin, detectedVia)
+
+	if len(pluginDaemonSets[plugin]) == 0 {
+		fmt.Fprintln(report, "  unknown, no DaemonSet found for the network plugin")
+	}
+
+	for _, daemonSet := range pluginDaemonSets[plugin] {
+		for i := range daemonSet.Spec.Template.Spec.Containers {
+			fmt.Fprintf(report, "  %s/%s %s: %s\n", daemonSet.Namespace, daemonSet.Name, daemonSet.Spec.Template.Spec.Containers[i].Name,
+				daemonSet.Spec.Template.Spec.Containers[i].Image)
+		}
+	}
+
+	fmt.Fprintln(report, "\nSettings:")
+
+	if clusterNetwork != nil {
+		writeSortedSettings(report, "", clusterNetwork.PluginSettings)
+	}
+
+	notes = append(notes, writeEncapsulationSettings(report, info, plugin, pluginDaemonSets[plugin])...)
+
+	if len(notes) > 0 {
+		fmt.Fprintf(report, "\nNotes:\n  %s\n", strings.Join(notes, "\n  "))
+	}
+
+	fileName, err := writeLogToFile(report.String(), "network-plugin", info, ".txt")
+	if err != nil {
+		info.Status.Failure("Error writing the network plugin details: %v", err)
+		return
+	}
+
+	info.Status.Success("Detected network plugin %q via %s", plugin, detectedVia)
+
+	info.Summary.Resources = append(info.Summary.Resources, ResourceInfo{
+		Name:     plugin,
+		Type:     "network-plugin",
+		FileName: fileName,
+	})
+}
+
+// detectNetworkPlugin prefers the plugin recorded by Submariner, then the discovered one, and finally the only plugin
+// with DaemonSets in the cluster, if there's just one; it returns notes describing any disagreement.
+func detectNetworkPlugin(info *Info, clusterNetwork *network.ClusterNetwork, pluginDaemonSets map[string][]*appsv1.DaemonSet,
+) (string, string, []string) {
+	notes := []string{}
+	plugin, detectedVia := cni.Generic, "none, no specific network plugin could be detected"
+
+	switch {
+	case info.Submariner != nil && info.Submariner.Status.NetworkPlugin != "":
+		plugin, detectedVia = info.Submariner.Status.NetworkPlugin, "the Submariner status"
+	case clusterNetwork != nil && clusterNetwork.NetworkPlugin != cni.Generic:
+		plugin, detectedVia = clusterNetwork.NetworkPlugin, "network discovery"
+	case len(pluginDaemonSets) == 1:
+		for p := range pluginDaemonSets {
+			plugin, detectedVia = p, "its DaemonSets"
+		}
+	}
+
+	if clusterNetwork != nil && clusterNetwork.NetworkPlugin != plugin {
+		notes = append(notes, fmt.Sprintf("Network discovery detected %q", clusterNetwork.NetworkPlugin))
+	}
+
+	candidates := []string{}
+
+	for p := range pluginDaemonSets {
+		if p != plugin {
+			candidates = append(candidates, p)
+		}
+	}
+
+	if len(candidates) > 0 {
+		sort.Strings(candidates)
+		notes = append(notes, fmt.Sprintf("DaemonSets were also found for %s", strings.Join(candidates, ", ")))
+	}
+
+	return plugin, detectedVia, notes
+}
+
+func writeEncapsulationSettings(report *strings.Builder, info *Info, plugin string, daemonSets []*appsv1.DaemonSet) []string {
+	notes := []string{}
+
+	if plugin == cni.Calico {
+		ipPools, err := info.ClientProducer.ForDynamic().Resource(calicoIPPoolGVR).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return append(notes, fmt.Sprintf("Error listing the Calico IPPools: %v", err))
+		}
+
+		for i := range ipPools.Items {
+			settings := map[string]string{}
+
+			for _, field := range []string{"cidr", "ipipMode", "vxlanMode", "natOutgoing", "disabled"} {
+				if value, found, _ := unstructured.NestedFieldNoCopy(ipPools.Items[i].Object, "spec", field); found {
+					settings[field] = fmt.Sprint(value)
+				}
+			}
+
+			writeSortedSettings(report, "IPPool "+ipPools.Items[i].GetName()+" ", settings)
+		}
+
+		return notes
+	}
+
+	keys := networkPluginConfigKeys[plugin]
+	if len(keys) == 0 {
+		return notes
+	}
+
+	for _, daemonSet := range daemonSets {
+		for _, volume := range daemonSet.Spec.Template.Spec.Volumes {
+			if volume.ConfigMap == nil {
+				continue
+			}
+
+			configMap, err := info.ClientProducer.ForKubernetes().CoreV1().ConfigMaps(daemonSet.Namespace).Get(context.TODO(),
+				volume.ConfigMap.Name, metav1.GetOptions{})
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("Error retrieving the ConfigMap %s/%s: %v", daemonSet.Namespace,
+					volume.ConfigMap.Name, err))
+
+				continue
+			}
+
+			settings := map[string]string{}
+
+			for _, key := range keys {
+				if value, ok := configMap.Data[key]; ok {
+					settings[key] = strings.TrimSpace(value)
+				}
+			}
+
+			writeSortedSettings(report, "ConfigMap "+configMap.Name+" ", settings)
+		}
+	}
+
+	return notes
+}
+
+func writeSortedSettings(report *strings.Builder, prefix string, settings map[string]string) {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(report, "  %s%s: %s\n", prefix, key, settings[key])
+	}
+}
@@ -34,6 +34,10 @@ func gatherServiceDiscoveries(info *Info, namespace string) {
 	ResourcesToYAMLFile(info, submarinerOp.GroupVersion.WithResource("servicediscoveries"), namespace, metav1.ListOptions{})
 }
 
+func gatherBrokers(info *Info, namespace string) {
+	ResourcesToYAMLFile(info, submarinerOp.GroupVersion.WithResource("brokers"), namespace, metav1.ListOptions{})
+}
+
 func gatherSubmarinerOperatorDeployment(info *Info, namespace string) {
 	gatherDeployment(info, namespace, metav1.ListOptions{FieldSelector: fields.Set(map[string]string{
 		"metadata.name": names.OperatorComponent,
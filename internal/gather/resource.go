@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/stringset"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,8 +35,12 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
+const redactedValue = "##redacted##"
+
 var fileNameRegexp = regexp.MustCompile(`[<>:"/\|?*]`)
 
+var sensitiveFields = stringset.New("ceIPSecPSK", "brokerK8sApiServerToken", "brokerK8sCA")
+
 //nolint:gocritic // hugeParam: listOptions - match K8s API.
 func ResourcesToYAMLFile(info *Info, ofType schema.GroupVersionResource, namespace string, listOptions metav1.ListOptions) {
 	err := func() error {
@@ -67,6 +72,10 @@ func ResourcesToYAMLFile(info *Info, ofType schema.GroupVersionResource, namespa
 
 			defer file.Close()
 
+			if info.Redact {
+				info.Summary.RedactedFields += redactSensitiveFields(item.Object)
+			}
+
 			data, err := yaml.Marshal(item)
 			if err != nil {
 				return errors.WithMessage(err, "error marshaling to YAML")
@@ -119,7 +128,10 @@ func scrubSensitiveData(info *Info, dataString string) string {
 		dataString = replaceIfNotEmpty(dataString, info.Submariner.Spec.BrokerK8sApiServerToken, "##redacted-token##")
 		dataString = replaceIfNotEmpty(dataString, info.Submariner.Spec.BrokerK8sCA, "##redacted-ca##")
 		dataString = replaceIfNotEmpty(dataString, info.Submariner.Spec.CeIPSecPSK, "##redacted-ipsec-psk##")
-	} else if info.ServiceDiscovery != nil {
+	}
+
+	// The service discovery credentials can differ from the connectivity ones, e.g. after a partial re-join
+	if info.ServiceDiscovery != nil {
 		dataString = replaceIfNotEmpty(dataString, info.ServiceDiscovery.Spec.BrokerK8sApiServer, "##redacted-api-server##")
 		dataString = replaceIfNotEmpty(dataString, info.ServiceDiscovery.Spec.BrokerK8sApiServerToken, "##redacted-token##")
 		dataString = replaceIfNotEmpty(dataString, info.ServiceDiscovery.Spec.BrokerK8sCA, "##redacted-ca##")
@@ -128,6 +140,49 @@ func scrubSensitiveData(info *Info, dataString string) string {
 	return dataString
 }
 
+// redactSensitiveFields replaces the values of the known sensitive fields anywhere in the given object, and all the data
+// of secrets, leaving the fields themselves in place. It returns the number of fields redacted.
+func redactSensitiveFields(obj map[string]interface{}) int {
+	redacted := 0
+
+	if obj["kind"] == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, ok := obj[field].(map[string]interface{}); ok {
+				for key := range data {
+					data[key] = redactedValue
+					redacted++
+				}
+			}
+		}
+	}
+
+	return redacted + redactFields(obj)
+}
+
+func redactFields(value interface{}) int {
+	redacted := 0
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if s, ok := field.(string); ok && s != "" && sensitiveFields.Contains(key) {
+				value[key] = redactedValue
+				redacted++
+
+				continue
+			}
+
+			redacted += redactFields(field)
+		}
+	case []interface{}:
+		for i := range value {
+			redacted += redactFields(value[i])
+		}
+	}
+
+	return redacted
+}
+
 func escapeFileName(s string) string {
 	return fileNameRegexp.ReplaceAllString(s, "_")
 }
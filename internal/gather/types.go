@@ -20,6 +20,7 @@ package gather
 
 import (
 	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/admiral/pkg/stringset"
 	"github.com/submariner-io/subctl/pkg/cluster"
 	"github.com/submariner-io/submariner-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
@@ -29,15 +30,19 @@ import (
 type Info struct {
 	cluster.Info
 	Status               reporter.Interface
+	Components           stringset.Interface
 	ClusterName          string
 	DirName              string
+	LogOptions           v1.PodLogOptions
 	IncludeSensitiveData bool
+	Redact               bool
 	Summary              *Summary
 }
 
 type Summary struct {
-	Resources []ResourceInfo
-	PodLogs   []LogInfo
+	Resources      []ResourceInfo
+	PodLogs        []LogInfo
+	RedactedFields int
 }
 
 type version struct {
@@ -66,6 +66,8 @@ type Config struct {
 	Command             string
 	Timeout             uint
 	ImageRepositoryInfo image.RepositoryInfo
+	// HostPaths are host directories mounted read-only at the same path in the pod.
+	HostPaths []string
 }
 
 type Scheduled struct {
@@ -167,6 +169,19 @@ func (np *Scheduled) schedule() error {
 		}
 	}
 
+	for i, path := range np.Config.HostPaths {
+		name := fmt.Sprintf("host-path-%d", i)
+		networkPod.Spec.Volumes = append(networkPod.Spec.Volumes, v1.Volume{
+			Name:         name,
+			VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: path}},
+		})
+		networkPod.Spec.Containers[0].VolumeMounts = append(networkPod.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
+			Name:      name,
+			MountPath: path,
+			ReadOnly:  true,
+		})
+	}
+
 	if np.Config.Scheduling.ScheduleOn == CustomNode {
 		networkPod.Spec.NodeName = np.Config.Scheduling.NodeName
 	} else {
@@ -19,7 +19,11 @@ limitations under the License.
 package restconfig
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/pkg/errors"
@@ -33,7 +37,9 @@ import (
 	"github.com/submariner-io/subctl/pkg/version"
 	"github.com/submariner-io/submariner-operator/api/v1alpha1"
 	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -63,11 +69,20 @@ type Producer struct {
 	contextsFlag              bool
 	defaultNamespace          *string
 	prefixedDefaultNamespaces map[string]*string
+	headerWriter              io.Writer
 }
 
 // NewProducer initialises a blank producer which needs to be set up with flags (see SetupFlags).
 func NewProducer() *Producer {
-	return &Producer{prefixedDefaultNamespaces: make(map[string]*string)}
+	return &Producer{prefixedDefaultNamespaces: make(map[string]*string), headerWriter: os.Stdout}
+}
+
+// WithHeadersTo configures the producer to write the header identifying each cluster processed by RunOnAllContexts
+// to the given writer, instead of standard output.
+func (rcp *Producer) WithHeadersTo(writer io.Writer) *Producer {
+	rcp.headerWriter = writer
+
+	return rcp
 }
 
 // WithNamespace configures the producer to set up a namespace flag.
@@ -409,14 +424,14 @@ func (rcp *Producer) RunOnAllContexts(function PerContextFn, status reporter.Int
 }
 
 func (rcp *Producer) overrideContextAndRun(clusterName, contextName string, function PerContextFn, status reporter.Interface) error {
-	fmt.Printf("Cluster %q\n", clusterName)
+	fmt.Fprintf(rcp.headerWriter, "Cluster %q\n", clusterName)
 
 	rcp.defaultClientConfig.overrides.CurrentContext = contextName
 	if err := rcp.RunOnSelectedContext(function, status); err != nil {
 		return err
 	}
 
-	fmt.Println()
+	fmt.Fprintln(rcp.headerWriter)
 
 	return nil
 }
@@ -428,28 +443,47 @@ func (rcp *Producer) PopulateTestFramework() {
 	}
 }
 
-func ForBroker(submariner *v1alpha1.Submariner, serviceDisc *v1alpha1.ServiceDiscovery) (*rest.Config, string, error) {
+func ForBroker(ctx context.Context, kubeClient kubernetes.Interface, submariner *v1alpha1.Submariner,
+	serviceDisc *v1alpha1.ServiceDiscovery,
+) (*rest.Config, string, error) {
 	var restConfig *rest.Config
 	var namespace string
 	var err error
 
-	// This is used in subctl; the broker secret isn't available mounted, so we use the old strings for now
+	// This is used in subctl; the broker secret isn't available mounted, so the inline strings are used when present,
+	// falling back to reading the broker secret when they were omitted
 	if submariner != nil {
+		var token, ca string
+
+		token, ca, err = brokerCredentials(ctx, kubeClient, submariner.Namespace, submariner.Spec.BrokerK8sSecret,
+			submariner.Spec.BrokerK8sApiServerToken, submariner.Spec.BrokerK8sCA)
+		if err != nil {
+			return nil, "", err
+		}
+
 		// Try to authorize against the submariner Cluster resource as we know the CRD should exist and the credentials
 		// should allow read access.
 		restConfig, _, err = resource.GetAuthorizedRestConfigFromData(submariner.Spec.BrokerK8sApiServer,
-			submariner.Spec.BrokerK8sApiServerToken,
-			submariner.Spec.BrokerK8sCA,
+			token,
+			ca,
 			&rest.TLSClientConfig{},
 			subv1.SchemeGroupVersion.WithResource("clusters"),
 			submariner.Spec.BrokerK8sRemoteNamespace)
 		namespace = submariner.Spec.BrokerK8sRemoteNamespace
 	} else if serviceDisc != nil {
+		var token, ca string
+
+		token, ca, err = brokerCredentials(ctx, kubeClient, serviceDisc.Namespace, serviceDisc.Spec.BrokerK8sSecret,
+			serviceDisc.Spec.BrokerK8sApiServerToken, serviceDisc.Spec.BrokerK8sCA)
+		if err != nil {
+			return nil, "", err
+		}
+
 		// Try to authorize against the ServiceImport resource as we know the CRD should exist and the credentials
 		// should allow read access.
 		restConfig, _, err = resource.GetAuthorizedRestConfigFromData(serviceDisc.Spec.BrokerK8sApiServer,
-			serviceDisc.Spec.BrokerK8sApiServerToken,
-			serviceDisc.Spec.BrokerK8sCA,
+			token,
+			ca,
 			&rest.TLSClientConfig{},
 			gvr.FromMetaGroupVersion(mcsv1a1.GroupVersion, "serviceimports"),
 			serviceDisc.Spec.BrokerK8sRemoteNamespace)
@@ -459,6 +493,22 @@ func ForBroker(submariner *v1alpha1.Submariner, serviceDisc *v1alpha1.ServiceDis
 	return restConfig, namespace, errors.Wrap(err, "error getting auth rest config")
 }
 
+// brokerCredentials returns the broker token and base64-encoded CA, from the inline fields if the token is set, or from
+// the named broker secret otherwise.
+func brokerCredentials(ctx context.Context, kubeClient kubernetes.Interface, namespace, secretName, token, ca string,
+) (string, string, error) {
+	if token != "" || secretName == "" || kubeClient == nil {
+		return token, ca, nil
+	}
+
+	brokerSecret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "error retrieving the broker secret %s/%s", namespace, secretName)
+	}
+
+	return string(brokerSecret.Data["token"]), base64.StdEncoding.EncodeToString(brokerSecret.Data["ca.crt"]), nil
+}
+
 func getRestConfigFromConfig(config clientcmd.ClientConfig, overrides *clientcmd.ConfigOverrides) (RestConfig, error) {
 	clientConfig, err := config.ClientConfig()
 	if err != nil {
@@ -20,14 +20,38 @@ package show
 
 import (
 	"errors"
+	"time"
 
 	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/restconfig"
 	"github.com/submariner-io/subctl/internal/show/table"
 	"github.com/submariner-io/subctl/pkg/cluster"
 	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 )
 
-func Connections(clusterInfo *cluster.Info, _ string, status reporter.Interface) error {
+type connectionInfo struct {
+	Gateway        string   `json:"gateway"`
+	Cluster        string   `json:"cluster"`
+	RemoteIP       string   `json:"remoteIP"`
+	NAT            bool     `json:"nat"`
+	CableDriver    string   `json:"cableDriver"`
+	Subnets        []string `json:"subnets"`
+	Status         string   `json:"status"`
+	StatusMessage  string   `json:"statusMessage,omitempty"`
+	RTTAverage     string   `json:"rttAverage,omitempty"`
+	RTTAverageNSec *int64   `json:"rttAverageNanoseconds,omitempty"`
+}
+
+func Connections(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	return showConnections(clusterInfo, namespace, NewOutput(FormatTable), status)
+}
+
+// ConnectionsIn returns a function showing the connections into the given output.
+func ConnectionsIn(output *Output) restconfig.PerContextFn {
+	return into(showConnections, output)
+}
+
+func showConnections(clusterInfo *cluster.Info, _ string, output *Output, status reporter.Interface) error {
 	status.Start("Showing Connections")
 
 	gateways, err := clusterInfo.GetGateways()
@@ -39,40 +63,58 @@ func Connections(clusterInfo *cluster.Info, _ string, status reporter.Interface)
 		return status.Error(errors.New("no gateways detected"), "")
 	}
 
-	printer := table.Printer{Columns: []table.Column{
-		{Name: "GATEWAY", MaxLength: 30},
-		{Name: "CLUSTER", MaxLength: 24},
-		{Name: "REMOTE IP"},
-		{Name: "NAT"},
-		{Name: "CABLE DRIVER"},
-		{Name: "SUBNETS", MaxLength: 40},
-		{Name: "STATUS"},
-		{Name: "RTT avg."},
-	}}
+	connections := []connectionInfo{}
 
 	for i := range gateways {
 		gateway := &gateways[i]
 		for i := range gateway.Status.Connections {
 			connection := &gateway.Status.Connections[i]
 			ip, nat := remoteIPAndNATForConnection(connection)
-			printer.Add(
-				connection.Endpoint.Hostname,
-				connection.Endpoint.ClusterID,
-				ip,
-				nat,
-				connection.Endpoint.Backend,
-				connection.Endpoint.Subnets,
-				connection.Status,
-				getAverageRTTForConnection(connection),
-			)
+			connections = append(connections, connectionInfo{
+				Gateway:        connection.Endpoint.Hostname,
+				Cluster:        connection.Endpoint.ClusterID,
+				RemoteIP:       ip,
+				NAT:            nat,
+				CableDriver:    connection.Endpoint.Backend,
+				Subnets:        connection.Endpoint.Subnets,
+				Status:         string(connection.Status),
+				StatusMessage:  connection.StatusMessage,
+				RTTAverage:     getAverageRTTForConnection(connection),
+				RTTAverageNSec: getAverageRTTNanosecondsForConnection(connection),
+			})
 		}
 	}
 
-	if printer.Empty() {
+	if output.Structured() {
+		status.End()
+		output.add(clusterInfo.Name, "connections", connections)
+
+		return nil
+	}
+
+	if len(connections) == 0 {
 		return status.Error(errors.New("no connections found"), "")
 	}
 
 	status.End()
+
+	printer := table.Printer{Columns: []table.Column{
+		{Name: "GATEWAY", MaxLength: 30},
+		{Name: "CLUSTER", MaxLength: 24},
+		{Name: "REMOTE IP"},
+		{Name: "NAT"},
+		{Name: "CABLE DRIVER"},
+		{Name: "SUBNETS", MaxLength: 40},
+		{Name: "STATUS"},
+		{Name: "RTT avg."},
+	}}
+
+	for i := range connections {
+		connection := &connections[i]
+		printer.Add(connection.Gateway, connection.Cluster, connection.RemoteIP, connection.NAT, connection.CableDriver,
+			connection.Subnets, connection.Status, connection.RTTAverage)
+	}
+
 	printer.Print()
 
 	return nil
@@ -87,6 +129,21 @@ func getAverageRTTForConnection(connection *submv1.Connection) string {
 	return rtt
 }
 
+func getAverageRTTNanosecondsForConnection(connection *submv1.Connection) *int64 {
+	if connection.LatencyRTT == nil {
+		return nil
+	}
+
+	rtt, err := time.ParseDuration(connection.LatencyRTT.Average)
+	if err != nil {
+		return nil
+	}
+
+	nanoseconds := rtt.Nanoseconds()
+
+	return &nanoseconds
+}
+
 func remoteIPAndNATForConnection(connection *submv1.Connection) (string, bool) {
 	if connection.UsingIP != "" {
 		return connection.UsingIP, connection.UsingNAT
@@ -22,11 +22,32 @@ import (
 	"errors"
 
 	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/restconfig"
 	"github.com/submariner-io/subctl/internal/show/table"
 	"github.com/submariner-io/subctl/pkg/cluster"
+	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 )
 
-func Endpoints(clusterInfo *cluster.Info, _ string, status reporter.Interface) error {
+type endpointInfo struct {
+	Cluster     string   `json:"cluster"`
+	Hostname    string   `json:"hostname"`
+	EndpointIP  string   `json:"endpointIP"`
+	PublicIP    string   `json:"publicIP"`
+	CableDriver string   `json:"cableDriver"`
+	Type        string   `json:"type"`
+	Subnets     []string `json:"subnets"`
+}
+
+func Endpoints(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	return showEndpoints(clusterInfo, namespace, NewOutput(FormatTable), status)
+}
+
+// EndpointsIn returns a function showing the endpoints into the given output.
+func EndpointsIn(output *Output) restconfig.PerContextFn {
+	return into(showEndpoints, output)
+}
+
+func showEndpoints(clusterInfo *cluster.Info, _ string, output *Output, status reporter.Interface) error {
 	status.Start("Showing Endpoints")
 
 	gateways, err := clusterInfo.GetGateways()
@@ -38,6 +59,25 @@ func Endpoints(clusterInfo *cluster.Info, _ string, status reporter.Interface) e
 		return status.Error(errors.New("no gateways detected"), "")
 	}
 
+	endpoints := []endpointInfo{}
+
+	for i := range gateways {
+		gateway := &gateways[i]
+		endpoints = append(endpoints, newEndpointInfo(&gateway.Status.LocalEndpoint, "local"))
+
+		for i := range gateway.Status.Connections {
+			endpoints = append(endpoints, newEndpointInfo(&gateway.Status.Connections[i].Endpoint, "remote"))
+		}
+	}
+
+	status.End()
+
+	if output.Structured() {
+		output.add(clusterInfo.Name, "endpoints", endpoints)
+
+		return nil
+	}
+
 	printer := table.Printer{Columns: []table.Column{
 		{Name: "CLUSTER", MaxLength: 24},
 		{Name: "ENDPOINT IP"},
@@ -46,30 +86,24 @@ func Endpoints(clusterInfo *cluster.Info, _ string, status reporter.Interface) e
 		{Name: "TYPE"},
 	}}
 
-	for i := range gateways {
-		gateway := &gateways[i]
-		printer.Add(
-			gateway.Status.LocalEndpoint.ClusterID,
-			gateway.Status.LocalEndpoint.PrivateIP,
-			gateway.Status.LocalEndpoint.PublicIP,
-			gateway.Status.LocalEndpoint.Backend,
-			"local",
-		)
-
-		for i := range gateway.Status.Connections {
-			connection := &gateway.Status.Connections[i]
-			printer.Add(
-				connection.Endpoint.ClusterID,
-				connection.Endpoint.PrivateIP,
-				connection.Endpoint.PublicIP,
-				connection.Endpoint.Backend,
-				"remote",
-			)
-		}
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		printer.Add(endpoint.Cluster, endpoint.EndpointIP, endpoint.PublicIP, endpoint.CableDriver, endpoint.Type)
 	}
 
-	status.End()
 	printer.Print()
 
 	return nil
 }
+
+func newEndpointInfo(endpoint *submv1.EndpointSpec, endpointType string) endpointInfo {
+	return endpointInfo{
+		Cluster:     endpoint.ClusterID,
+		Hostname:    endpoint.Hostname,
+		EndpointIP:  endpoint.PrivateIP,
+		PublicIP:    endpoint.PublicIP,
+		CableDriver: endpoint.Backend,
+		Type:        endpointType,
+		Subnets:     endpoint.Subnets,
+	}
+}
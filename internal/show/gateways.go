@@ -23,12 +23,31 @@ import (
 	"fmt"
 
 	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/restconfig"
 	"github.com/submariner-io/subctl/internal/show/table"
 	"github.com/submariner-io/subctl/pkg/cluster"
 	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 )
 
-func Gateways(clusterInfo *cluster.Info, _ string, status reporter.Interface) error {
+type gatewayInfo struct {
+	Node          string `json:"node"`
+	HAStatus      string `json:"haStatus"`
+	Summary       string `json:"summary"`
+	StatusFailure string `json:"statusFailure,omitempty"`
+	Connections   int    `json:"connections"`
+	Connected     int    `json:"connected"`
+}
+
+func Gateways(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	return showGateways(clusterInfo, namespace, NewOutput(FormatTable), status)
+}
+
+// GatewaysIn returns a function showing the gateways into the given output.
+func GatewaysIn(output *Output) restconfig.PerContextFn {
+	return into(showGateways, output)
+}
+
+func showGateways(clusterInfo *cluster.Info, _ string, output *Output, status reporter.Interface) error {
 	status.Start("Showing Gateways")
 
 	gateways, err := clusterInfo.GetGateways()
@@ -40,11 +59,7 @@ func Gateways(clusterInfo *cluster.Info, _ string, status reporter.Interface) er
 		return status.Error(errors.New("no gateways detected"), "")
 	}
 
-	printer := table.Printer{Columns: []table.Column{
-		{Name: "NODE", MaxLength: 30},
-		{Name: "HA STATUS"},
-		{Name: "SUMMARY"},
-	}}
+	gatewayInfos := make([]gatewayInfo, len(gateways))
 
 	for i := range gateways {
 		gateway := gateways[i]
@@ -68,10 +83,34 @@ func Gateways(clusterInfo *cluster.Info, _ string, status reporter.Interface) er
 			summary = fmt.Sprintf("%d connections out of %d are established", countConnected, totalConnections)
 		}
 
-		printer.Add(gateway.Status.LocalEndpoint.Hostname, gateway.Status.HAStatus, summary)
+		gatewayInfos[i] = gatewayInfo{
+			Node:          gateway.Status.LocalEndpoint.Hostname,
+			HAStatus:      string(gateway.Status.HAStatus),
+			Summary:       summary,
+			StatusFailure: gateway.Status.StatusFailure,
+			Connections:   totalConnections,
+			Connected:     countConnected,
+		}
 	}
 
 	status.End()
+
+	if output.Structured() {
+		output.add(clusterInfo.Name, "gateways", gatewayInfos)
+
+		return nil
+	}
+
+	printer := table.Printer{Columns: []table.Column{
+		{Name: "NODE", MaxLength: 30},
+		{Name: "HA STATUS"},
+		{Name: "SUMMARY"},
+	}}
+
+	for i := range gatewayInfos {
+		printer.Add(gatewayInfos[i].Node, gatewayInfos[i].HAStatus, gatewayInfos[i].Summary)
+	}
+
 	printer.Print()
 
 	return nil
@@ -24,19 +24,39 @@ import (
 
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/restconfig"
 	"github.com/submariner-io/subctl/pkg/cluster"
 	"github.com/submariner-io/submariner-operator/pkg/discovery/network"
 )
 
-func Network(clusterInfo *cluster.Info, _ string, status reporter.Interface) error {
+type networkInfo struct {
+	DiscoveredVia  string            `json:"discoveredVia"`
+	NetworkPlugin  string            `json:"networkPlugin,omitempty"`
+	GlobalCIDR     string            `json:"globalCIDR,omitempty"`
+	PodCIDRs       []string          `json:"podCIDRs,omitempty"`
+	ServiceCIDRs   []string          `json:"serviceCIDRs,omitempty"`
+	PluginSettings map[string]string `json:"pluginSettings,omitempty"`
+}
+
+func Network(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	return showNetwork(clusterInfo, namespace, NewOutput(FormatTable), status)
+}
+
+// NetworkIn returns a function showing the network details into the given output.
+func NetworkIn(output *Output) restconfig.PerContextFn {
+	return into(showNetwork, output)
+}
+
+func showNetwork(clusterInfo *cluster.Info, _ string, output *Output, status reporter.Interface) error {
 	status.Start("Showing Network details")
 
 	var clusterNetwork *network.ClusterNetwork
-	var msg string
+	var msg, discoveredVia string
 	var err error
 
 	if clusterInfo.Submariner != nil {
 		msg = "    Discovered network details via Submariner:"
+		discoveredVia = "submariner"
 		clusterNetwork = &network.ClusterNetwork{
 			PodCIDRs:      []string{clusterInfo.Submariner.Status.ClusterCIDR},
 			ServiceCIDRs:  []string{clusterInfo.Submariner.Status.ServiceCIDR},
@@ -45,6 +65,7 @@ func Network(clusterInfo *cluster.Info, _ string, status reporter.Interface) err
 		}
 	} else {
 		msg = "    Discovered network details"
+		discoveredVia = "discovery"
 
 		clusterNetwork, err = network.Discover(context.TODO(), clusterInfo.ClientProducer.ForGeneral(), constants.OperatorNamespace)
 		if err != nil {
@@ -58,6 +79,21 @@ func Network(clusterInfo *cluster.Info, _ string, status reporter.Interface) err
 
 	status.End()
 
+	if output.Structured() {
+		info := networkInfo{DiscoveredVia: discoveredVia}
+		if clusterNetwork != nil {
+			info.NetworkPlugin = clusterNetwork.NetworkPlugin
+			info.GlobalCIDR = clusterNetwork.GlobalCIDR
+			info.PodCIDRs = clusterNetwork.PodCIDRs
+			info.ServiceCIDRs = clusterNetwork.ServiceCIDRs
+			info.PluginSettings = clusterNetwork.PluginSettings
+		}
+
+		output.add(clusterInfo.Name, "network", info)
+
+		return nil
+	}
+
 	if clusterNetwork != nil {
 		fmt.Println(msg)
 		clusterNetwork.Show()
@@ -0,0 +1,109 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package show
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/restconfig"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"sigs.k8s.io/yaml"
+)
+
+// Format is an output format for the show functions.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+// ParseFormat returns the output format with the given name.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatTable, FormatJSON, FormatYAML:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unsupported output format %q, use one of %q, %q or %q", name, FormatTable, FormatJSON, FormatYAML)
+}
+
+type showFn func(clusterInfo *cluster.Info, namespace string, output *Output, status reporter.Interface) error
+
+func into(function showFn, output *Output) restconfig.PerContextFn {
+	return func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+		return function(clusterInfo, namespace, output, status)
+	}
+}
+
+// Output collects the information shown for each cluster in a structured format, so that it can be printed as a single
+// document once all the clusters have been processed. Tables are printed as each cluster is processed.
+type Output struct {
+	format   Format
+	clusters []map[string]interface{}
+}
+
+// NewOutput returns an output in the given format.
+func NewOutput(format Format) *Output {
+	return &Output{format: format, clusters: []map[string]interface{}{}}
+}
+
+// Structured returns true if the output is in a structured format.
+func (o *Output) Structured() bool {
+	return o.format != FormatTable
+}
+
+func (o *Output) add(clusterName, key string, items interface{}) {
+	o.clusters = append(o.clusters, map[string]interface{}{
+		"cluster": clusterName,
+		key:       items,
+	})
+}
+
+// Print prints the collected information as a list with an entry per cluster, in the output's structured format.
+// It doesn't print anything for tables.
+func (o *Output) Print() error {
+	if !o.Structured() {
+		return nil
+	}
+
+	if o.format == FormatYAML {
+		output, err := yaml.Marshal(o.clusters)
+		if err != nil {
+			return errors.Wrap(err, "error marshalling the output to YAML")
+		}
+
+		fmt.Print(string(output))
+
+		return nil
+	}
+
+	output, err := json.MarshalIndent(o.clusters, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error marshalling the output to JSON")
+	}
+
+	fmt.Println(string(output))
+
+	return nil
+}
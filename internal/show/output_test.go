@@ -0,0 +1,229 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package show_test
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/restconfig"
+	"github.com/submariner-io/subctl/internal/show"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("ParseFormat", func() {
+	DescribeTable("supported formats",
+		func(name string, expected show.Format) {
+			format, err := show.ParseFormat(name)
+			Expect(err).To(Succeed())
+			Expect(format).To(Equal(expected))
+		},
+		Entry("table", "table", show.FormatTable),
+		Entry("JSON", "json", show.FormatJSON),
+		Entry("YAML", "yaml", show.FormatYAML),
+	)
+
+	It("should reject other formats", func() {
+		_, err := show.ParseFormat("xml")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Structured output", func() {
+	var gateways []controllerClient.Object
+
+	BeforeEach(func() {
+		gateways = []controllerClient.Object{&submarinerv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: constants.OperatorNamespace},
+			Status: submarinerv1.GatewayStatus{
+				HAStatus:      submarinerv1.HAStatusActive,
+				LocalEndpoint: submarinerv1.EndpointSpec{Hostname: "node-east"},
+				Connections: []submarinerv1.Connection{
+					{
+						Status: submarinerv1.Connected,
+						Endpoint: submarinerv1.EndpointSpec{
+							Hostname: "node-west", ClusterID: "west", Backend: "libreswan", Subnets: []string{"10.1.0.0/16"},
+						},
+						UsingIP:    "1.1.1.1",
+						UsingNAT:   true,
+						LatencyRTT: &submarinerv1.LatencyRTTSpec{Average: "1.5ms"},
+					},
+					{
+						Status:   submarinerv1.Connecting,
+						Endpoint: submarinerv1.EndpointSpec{ClusterID: "north", NATEnabled: true, PublicIP: "2.2.2.2", PrivateIP: "10.2.0.1"},
+					},
+				},
+			},
+		}}
+	})
+
+	newClusterInfo := func(name string, objects ...controllerClient.Object) *cluster.Info {
+		scheme := runtime.NewScheme()
+		Expect(submarinerv1.AddToScheme(scheme)).To(Succeed())
+
+		return &cluster.Info{
+			Name: name,
+			ClientProducer: &client.DefaultProducer{
+				GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			},
+		}
+	}
+
+	run := func(function func(*show.Output) restconfig.PerContextFn, format show.Format, clusterInfos ...*cluster.Info,
+	) (string, error) {
+		if len(clusterInfos) == 0 {
+			clusterInfos = []*cluster.Info{newClusterInfo("east", gateways...)}
+		}
+
+		reader, writer, err := os.Pipe()
+		Expect(err).To(Succeed())
+
+		stdout := os.Stdout
+		os.Stdout = writer
+
+		output := show.NewOutput(format)
+		errs := []error{}
+
+		for _, clusterInfo := range clusterInfos {
+			errs = append(errs, function(output)(clusterInfo, constants.OperatorNamespace, reporter.Silent()))
+		}
+
+		errs = append(errs, output.Print())
+
+		os.Stdout = stdout
+		Expect(writer.Close()).To(Succeed())
+
+		printed, readErr := io.ReadAll(reader)
+		Expect(readErr).To(Succeed())
+
+		return string(printed), k8serrors.NewAggregate(errs)
+	}
+
+	It("should show the connections in JSON", func() {
+		output, err := run(show.ConnectionsIn, show.FormatJSON)
+		Expect(err).To(Succeed())
+
+		documents := []map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(output), &documents)).To(Succeed())
+		Expect(documents).To(HaveLen(1))
+		Expect(documents[0]).To(HaveKeyWithValue("cluster", "east"))
+		Expect(documents[0]["connections"]).To(ConsistOf(
+			SatisfyAll(
+				HaveKeyWithValue("gateway", "node-west"),
+				HaveKeyWithValue("cluster", "west"),
+				HaveKeyWithValue("remoteIP", "1.1.1.1"),
+				HaveKeyWithValue("nat", true),
+				HaveKeyWithValue("cableDriver", "libreswan"),
+				HaveKeyWithValue("status", "connected"),
+				HaveKeyWithValue("rttAverage", "1.5ms"),
+				HaveKeyWithValue("rttAverageNanoseconds", float64(1500000)),
+			),
+			SatisfyAll(
+				HaveKeyWithValue("cluster", "north"),
+				HaveKeyWithValue("remoteIP", "2.2.2.2"),
+				HaveKeyWithValue("nat", true),
+				HaveKeyWithValue("status", "connecting"),
+				Not(HaveKey("rttAverageNanoseconds")),
+			),
+		))
+	})
+
+	It("should show the gateways in YAML", func() {
+		output, err := run(show.GatewaysIn, show.FormatYAML)
+		Expect(err).To(Succeed())
+
+		documents := []map[string]interface{}{}
+		Expect(yaml.Unmarshal([]byte(output), &documents)).To(Succeed())
+		Expect(documents).To(HaveLen(1))
+		Expect(documents[0]).To(HaveKeyWithValue("cluster", "east"))
+		Expect(documents[0]["gateways"]).To(ConsistOf(SatisfyAll(
+			HaveKeyWithValue("node", "node-east"),
+			HaveKeyWithValue("haStatus", "active"),
+			HaveKeyWithValue("summary", "1 connections out of 2 are established"),
+			HaveKeyWithValue("connections", float64(2)),
+			HaveKeyWithValue("connected", float64(1)),
+		)))
+	})
+
+	When("several clusters are shown", func() {
+		It("should print a single document with an entry per cluster", func() {
+			output, err := run(show.GatewaysIn, show.FormatJSON, newClusterInfo("east", gateways...),
+				newClusterInfo("west", &submarinerv1.Gateway{
+					ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: constants.OperatorNamespace},
+					Status: submarinerv1.GatewayStatus{
+						HAStatus:      submarinerv1.HAStatusActive,
+						LocalEndpoint: submarinerv1.EndpointSpec{Hostname: "node-west"},
+					},
+				}))
+			Expect(err).To(Succeed())
+
+			documents := []map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(output), &documents)).To(Succeed())
+			Expect(documents).To(HaveLen(2))
+			Expect(documents[0]).To(HaveKeyWithValue("cluster", "east"))
+			Expect(documents[1]).To(HaveKeyWithValue("cluster", "west"))
+			Expect(documents[1]["gateways"]).To(ConsistOf(HaveKeyWithValue("summary", "There are no connections")))
+		})
+	})
+
+	When("there are no connections", func() {
+		It("should show an empty list", func() {
+			gateways[0].(*submarinerv1.Gateway).Status.Connections = nil
+
+			output, err := run(show.ConnectionsIn, show.FormatJSON)
+			Expect(err).To(Succeed())
+
+			documents := []map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(output), &documents)).To(Succeed())
+			Expect(documents).To(HaveLen(1))
+			Expect(documents[0]).To(HaveKeyWithValue("connections", BeEmpty()))
+		})
+	})
+
+	When("there are no gateways", func() {
+		It("should return an error and leave the cluster out of the output", func() {
+			gateways = nil
+
+			output, err := run(show.GatewaysIn, show.FormatJSON)
+			Expect(err).To(HaveOccurred())
+			Expect(output).To(MatchJSON("[]"))
+		})
+	})
+
+	When("the output is a table", func() {
+		It("should not print a structured document", func() {
+			output := show.NewOutput(show.FormatTable)
+			Expect(output.Structured()).To(BeFalse())
+			Expect(output.Print()).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,76 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// DescribeBrokerSecret returns a description of the given broker secret which is safe to share: the token itself is
+// never included, only whether it is present, and the CA is summarized by its SHA-256 fingerprint.
+func DescribeBrokerSecret(secret *v1.Secret) string {
+	var description strings.Builder
+
+	fmt.Fprintf(&description, "Secret:           %s/%s\n", secret.Namespace, secret.Name)
+	fmt.Fprintf(&description, "Broker namespace: %s\n", valueOrNone(string(secret.Data["namespace"])))
+	fmt.Fprintf(&description, "CA fingerprint:   %s\n", valueOrNone(caFingerprint(secret.Data["ca.crt"])))
+
+	if token := secret.Data["token"]; len(token) > 0 {
+		fmt.Fprintf(&description, "Token:            present (%d bytes)\n", len(token))
+	} else {
+		fmt.Fprintf(&description, "Token:            missing\n")
+	}
+
+	return description.String()
+}
+
+// caFingerprint returns the SHA-256 fingerprint of the first certificate in the given PEM data, formatted like
+// "openssl x509 -fingerprint -sha256" does.
+func caFingerprint(caData []byte) string {
+	if len(caData) == 0 {
+		return ""
+	}
+
+	der := caData
+	if block, _ := pem.Decode(caData); block != nil {
+		der = block.Bytes
+	}
+
+	sum := sha256.Sum256(der)
+	hexBytes := make([]string, len(sum))
+
+	for i, b := range sum {
+		hexBytes[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(hexBytes, ":")
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+
+	return value
+}
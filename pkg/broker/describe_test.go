@@ -0,0 +1,58 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/broker"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("DescribeBrokerSecret", func() {
+	When("the secret is complete", func() {
+		It("should describe it without revealing the token", func() {
+			description := broker.DescribeBrokerSecret(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "submariner-operator", Name: "broker-secret-abcde"},
+				Data: map[string][]byte{
+					"ca.crt":    []byte("not-really-a-certificate"),
+					"namespace": []byte("submariner-k8s-broker"),
+					"token":     []byte("very-secret-token"),
+				},
+			})
+
+			Expect(description).To(ContainSubstring("submariner-operator/broker-secret-abcde"))
+			Expect(description).To(ContainSubstring("submariner-k8s-broker"))
+			Expect(description).To(MatchRegexp(`CA fingerprint:\s+([0-9A-F]{2}:){31}[0-9A-F]{2}`))
+			Expect(description).To(ContainSubstring("present (17 bytes)"))
+			Expect(description).ToNot(ContainSubstring("very-secret-token"))
+		})
+	})
+
+	When("the secret is empty", func() {
+		It("should report the missing fields", func() {
+			description := broker.DescribeBrokerSecret(&v1.Secret{})
+
+			Expect(description).To(ContainSubstring("Broker namespace: <none>"))
+			Expect(description).To(ContainSubstring("CA fingerprint:   <none>"))
+			Expect(description).To(ContainSubstring("Token:            missing"))
+		})
+	})
+})
@@ -21,6 +21,7 @@ package broker
 import (
 	"context"
 	goerrors "errors"
+	"fmt"
 	"time"
 
 	"github.com/pkg/errors"
@@ -34,6 +35,7 @@ import (
 	"github.com/submariner-io/submariner-operator/pkg/crd"
 	"github.com/submariner-io/submariner-operator/pkg/lighthouse"
 	"github.com/submariner-io/submariner-operator/pkg/names"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -42,6 +44,15 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// MinClientTokenTTL is the shortest expiration accepted by the TokenRequest API.
+	MinClientTokenTTL = 10 * time.Minute
+	// MaxClientTokenTTL is the longest expiration accepted for bound tokens. Bound tokens are copied once into the
+	// cluster's broker secret and nothing refreshes them: the cluster loses its broker connectivity when the token
+	// expires, and must be joined again before then.
+	MaxClientTokenTTL = 365 * 24 * time.Hour
+)
+
 func Ensure(ctx context.Context, crdUpdater crd.Updater, kubeClient kubernetes.Interface, componentArr []string, createCRDs bool,
 	brokerNS string,
 ) error {
@@ -135,6 +146,49 @@ func CreateSAForCluster(ctx context.Context, kubeClient kubernetes.Interface, cl
 	return clientToken, nil
 }
 
+// ValidateClientTokenTTL checks that the given bound token lifetime is accepted by the Kubernetes TokenRequest API.
+func ValidateClientTokenTTL(ttl time.Duration) error {
+	if ttl < MinClientTokenTTL || ttl > MaxClientTokenTTL {
+		return fmt.Errorf("the broker token TTL must be between %v and %v, got %v", MinClientTokenTTL, MaxClientTokenTTL, ttl)
+	}
+
+	return nil
+}
+
+// RequestBoundClientToken requests a token bound to the given cluster's SA, expiring after the given TTL. The returned
+// bool is false if the broker doesn't support (or doesn't allow us to use) the TokenRequest API, in which case the
+// caller should keep using the long-lived token. The token isn't refreshed; once it expires, the cluster can no longer
+// access the broker.
+func RequestBoundClientToken(ctx context.Context, kubeClient kubernetes.Interface, clusterID, inNamespace string,
+	ttl time.Duration,
+) (string, bool, error) {
+	expirationSeconds := int64(ttl.Seconds())
+
+	tokenRequest, err := kubeClient.CoreV1().ServiceAccounts(inNamespace).CreateToken(ctx, names.ForClusterSA(clusterID),
+		&authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+		}, metav1.CreateOptions{})
+	if apierrors.IsNotFound(err) {
+		// A missing SA is an error, only a missing token subresource means the API isn't supported
+		_, saErr := kubeClient.CoreV1().ServiceAccounts(inNamespace).Get(ctx, names.ForClusterSA(clusterID), metav1.GetOptions{})
+		if saErr != nil {
+			return "", false, errors.Wrap(saErr, "error retrieving the cluster sa")
+		}
+
+		return "", false, nil
+	}
+
+	if apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, errors.Wrap(err, "error requesting a bound token for the cluster sa")
+	}
+
+	return tokenRequest.Status.Token, true, nil
+}
+
 func createBrokerAdministratorRoleAndSA(ctx context.Context, kubeClient kubernetes.Interface, inNamespace string) error {
 	// Create the SA we need for the managing the broker (from subctl, etc..).
 	err := CreateNewBrokerSA(ctx, kubeClient, constants.SubmarinerBrokerAdminSA, inNamespace)
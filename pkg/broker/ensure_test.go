@@ -0,0 +1,81 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("RequestBoundClientToken", func() {
+	const (
+		clusterID = "east"
+		namespace = "submariner-k8s-broker"
+	)
+
+	var kubeClient *fake.Clientset
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset()
+		kubeClient.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "token" {
+				return false, nil, nil
+			}
+
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "serviceaccounts/token"}, names.ForClusterSA(clusterID))
+		})
+	})
+
+	request := func() (bool, error) {
+		_, supported, err := broker.RequestBoundClientToken(context.TODO(), kubeClient, clusterID, namespace, time.Hour)
+		return supported, err
+	}
+
+	When("the token subresource isn't found but the service account exists", func() {
+		It("should report the API as unsupported", func() {
+			_, err := kubeClient.CoreV1().ServiceAccounts(namespace).Create(context.TODO(), &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: names.ForClusterSA(clusterID)},
+			}, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+
+			supported, err := request()
+			Expect(err).To(Succeed())
+			Expect(supported).To(BeFalse())
+		})
+	})
+
+	When("the service account doesn't exist", func() {
+		It("should return an error", func() {
+			_, err := request()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
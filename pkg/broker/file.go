@@ -32,6 +32,7 @@ import (
 	"github.com/submariner-io/subctl/internal/component"
 	"github.com/submariner-io/subctl/internal/constants"
 	"github.com/submariner-io/subctl/internal/rbac"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -56,6 +57,7 @@ func WriteInfoToFile(restConfig *rest.Config, brokerNamespace, ipsecFile string,
 	}
 
 	data.BrokerURL = restConfig.Host + restConfig.APIPath
+	data.IssuedAt = &metav1.Time{Time: time.Now()}
 
 	newFilename, err := backupIfExists(InfoFileName)
 	if err != nil {
@@ -92,6 +94,31 @@ func ReadInfoFromFile(filename string) (*Info, error) {
 	return data, errors.Wrap(json.Unmarshal(bytes, data), "error unmarshalling data")
 }
 
+// UpdateIPSecPSKInFile replaces the IPsec PSK stored in the given broker information file, so that clusters joined
+// with it use the given PSK. The previous file is backed up.
+func UpdateIPSecPSKInFile(filename string, psk []byte) error {
+	data, err := ReadInfoFromFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if data.IPSecPSK == nil {
+		data.IPSecPSK, err = newIPSECPSKSecret()
+		if err != nil {
+			return err
+		}
+	}
+
+	data.IPSecPSK.Data = map[string][]byte{"psk": psk}
+
+	_, err = backupIfExists(filename)
+	if err != nil {
+		return errors.Wrapf(err, "error backing up the broker file %q", filename)
+	}
+
+	return data.writeToFile(filename)
+}
+
 func newDataFrom(kubeClient kubernetes.Interface, brokerNamespace, ipsecFile string) (*Info, error) {
 	var err error
 	data := &Info{}
@@ -20,9 +20,12 @@ package broker
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/resource"
@@ -42,6 +45,48 @@ type Info struct {
 	ServiceDiscovery bool           `omitempty,json:"serviceDiscovery"`
 	Components       []string       `json:",omitempty"`
 	CustomDomains    *[]string      `omitempty,json:"customDomains"`
+	IssuedAt         *metav1.Time   `json:",omitempty"`
+}
+
+// CheckBrokerInfoFreshness verifies that the given broker information was issued less than maxAge ago. Broker information
+// without an issue timestamp, written by older versions of subctl, is accepted.
+func CheckBrokerInfoFreshness(info *Info, maxAge time.Duration) error {
+	if info.IssuedAt == nil {
+		return nil
+	}
+
+	if age := time.Since(info.IssuedAt.Time); age > maxAge {
+		return fmt.Errorf("the broker information was issued %v ago, on %s, which is more than the allowed %v; "+
+			"consider regenerating it", age.Round(time.Hour), info.IssuedAt.Format(time.RFC3339), maxAge)
+	}
+
+	return nil
+}
+
+// ReadCABundle reads the PEM-encoded CA bundle in the given file, and verifies that it contains at least one certificate.
+func ReadCABundle(filename string) ([]byte, error) {
+	caBundle, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading the CA bundle %q", filename)
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("%q doesn't contain any PEM-encoded certificates", filename)
+	}
+
+	return caBundle, nil
+}
+
+// PinCA replaces the CA in the client token with the given bundle, so that the broker's certificate is verified against
+// it, both by subctl and by the deployed components.
+func (d *Info) PinCA(caBundle []byte) {
+	clientToken := d.ClientToken.DeepCopy()
+	if clientToken.Data == nil {
+		clientToken.Data = map[string][]byte{}
+	}
+
+	clientToken.Data["ca.crt"] = caBundle
+	d.ClientToken = clientToken
 }
 
 func (d *Info) writeToFile(filename string) error {
@@ -66,22 +111,26 @@ func (d *Info) encode() (string, error) {
 	return base64.URLEncoding.EncodeToString(jsonBytes), nil
 }
 
-func (d *Info) GetBrokerAdministratorConfig(ctx context.Context, insecure bool) (*rest.Config, error) {
+// GetBrokerAdministratorConfig returns a checked configuration to connect to the broker. If proxy is non-nil, it
+// determines the proxy used for the broker connection, instead of the environment.
+func (d *Info) GetBrokerAdministratorConfig(ctx context.Context, insecure bool, proxy ProxyFunc) (*rest.Config, error) {
 	if insecure {
-		return d.getAndCheckBrokerAdministratorConfig(ctx, false, true)
+		return d.getAndCheckBrokerAdministratorConfig(ctx, false, true, proxy)
 	}
 	// We need to try a connection to determine whether the trust chain needs to be provided
-	config, err := d.getAndCheckBrokerAdministratorConfig(ctx, false, false)
+	config, err := d.getAndCheckBrokerAdministratorConfig(ctx, false, false, proxy)
 	if resource.IsUnknownAuthorityError(err) {
 		// Certificate error, try with the trust chain
-		config, err = d.getAndCheckBrokerAdministratorConfig(ctx, true, false)
+		config, err = d.getAndCheckBrokerAdministratorConfig(ctx, true, false, proxy)
 	}
 
 	return config, err
 }
 
-func (d *Info) getAndCheckBrokerAdministratorConfig(ctx context.Context, private, insecure bool) (*rest.Config, error) {
+func (d *Info) getAndCheckBrokerAdministratorConfig(ctx context.Context, private, insecure bool, proxy ProxyFunc,
+) (*rest.Config, error) {
 	config := d.getBrokerAdministratorConfig(private, insecure)
+	config.Proxy = proxy
 
 	submClientset, err := submarinerClientset.NewForConfig(config)
 	if err != nil {
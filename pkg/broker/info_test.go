@@ -0,0 +1,192 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/broker"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("CheckBrokerInfoFreshness", func() {
+	const maxAge = 24 * time.Hour
+
+	issuedAgo := func(age time.Duration) *broker.Info {
+		return &broker.Info{IssuedAt: &metav1.Time{Time: time.Now().Add(-age)}}
+	}
+
+	When("the broker information is recent", func() {
+		It("should succeed", func() {
+			Expect(broker.CheckBrokerInfoFreshness(issuedAgo(time.Hour), maxAge)).To(Succeed())
+		})
+	})
+
+	When("the broker information is too old", func() {
+		It("should return an error", func() {
+			Expect(broker.CheckBrokerInfoFreshness(issuedAgo(48*time.Hour), maxAge)).ToNot(Succeed())
+		})
+	})
+
+	When("the broker information has no timestamp", func() {
+		It("should succeed", func() {
+			Expect(broker.CheckBrokerInfoFreshness(&broker.Info{}, maxAge)).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("NewProxyFunc", func() {
+	proxyFor := func(proxyFunc broker.ProxyFunc, target string) string {
+		targetURL, err := url.Parse(target)
+		Expect(err).To(Succeed())
+
+		proxyURL, err := proxyFunc(&http.Request{URL: targetURL})
+		Expect(err).To(Succeed())
+
+		if proxyURL == nil {
+			return ""
+		}
+
+		return proxyURL.String()
+	}
+
+	It("should use the proxy matching the request scheme", func() {
+		proxyFunc, err := broker.NewProxyFunc("", "http://proxy.example.com:3128")
+		Expect(err).To(Succeed())
+		Expect(proxyFor(proxyFunc, "https://broker.example.com:6443")).To(Equal("http://proxy.example.com:3128"))
+		Expect(proxyFor(proxyFunc, "http://broker.example.com")).To(BeEmpty())
+	})
+
+	It("should reject invalid proxy URLs", func() {
+		_, err := broker.NewProxyFunc("proxy.example.com:3128", "")
+		Expect(err).To(HaveOccurred())
+
+		_, err = broker.NewProxyFunc("", "ftp://proxy.example.com")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("UpdateIPSecPSKInFile", func() {
+	var filename string
+
+	BeforeEach(func() {
+		filename = filepath.Join(GinkgoT().TempDir(), broker.InfoFileName)
+
+		raw, err := json.Marshal(&broker.Info{BrokerURL: "https://broker.example.com:6443"})
+		Expect(err).To(Succeed())
+		Expect(os.WriteFile(filename, []byte(base64.URLEncoding.EncodeToString(raw)), 0o600)).To(Succeed())
+	})
+
+	It("should store the new PSK and preserve the rest of the information", func() {
+		Expect(broker.UpdateIPSecPSKInFile(filename, []byte("new-psk"))).To(Succeed())
+
+		info, err := broker.ReadInfoFromFile(filename)
+		Expect(err).To(Succeed())
+		Expect(info.BrokerURL).To(Equal("https://broker.example.com:6443"))
+		Expect(info.IPSecPSK).ToNot(BeNil())
+		Expect(info.IPSecPSK.Data["psk"]).To(Equal([]byte("new-psk")))
+	})
+
+	It("should back up the previous file", func() {
+		Expect(broker.UpdateIPSecPSKInFile(filename, []byte("new-psk"))).To(Succeed())
+
+		backups, err := filepath.Glob(filename + ".*")
+		Expect(err).To(Succeed())
+		Expect(backups).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Pinning the broker CA", func() {
+	var directory string
+
+	BeforeEach(func() {
+		directory = GinkgoT().TempDir()
+	})
+
+	writeFile := func(contents []byte) string {
+		filename := filepath.Join(directory, "ca.crt")
+		Expect(os.WriteFile(filename, contents, 0o600)).To(Succeed())
+
+		return filename
+	}
+
+	When("the file contains a certificate", func() {
+		It("should pin it in the client token without modifying the original", func() {
+			caBundle, err := broker.ReadCABundle(writeFile(newTestCACertificate()))
+			Expect(err).To(Succeed())
+
+			original := &corev1.Secret{Data: map[string][]byte{"ca.crt": []byte("broker-ca"), "token": []byte("token")}}
+			info := &broker.Info{ClientToken: original}
+			info.PinCA(caBundle)
+
+			Expect(info.ClientToken.Data["ca.crt"]).To(Equal(caBundle))
+			Expect(info.ClientToken.Data["token"]).To(Equal([]byte("token")))
+			Expect(original.Data["ca.crt"]).To(Equal([]byte("broker-ca")))
+		})
+	})
+
+	When("the file doesn't contain a certificate", func() {
+		It("should return an error", func() {
+			_, err := broker.ReadCABundle(writeFile([]byte("not a certificate")))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the file doesn't exist", func() {
+		It("should return an error", func() {
+			_, err := broker.ReadCABundle(filepath.Join(directory, "missing.crt"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+func newTestCACertificate() []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(Succeed())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "broker-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).To(Succeed())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
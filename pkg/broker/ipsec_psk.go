@@ -26,7 +26,7 @@ import (
 )
 
 const (
-	ipsecPSKSecretName = "submariner-ipsec-psk"
+	IPSecPSKSecretName = "submariner-ipsec-psk"
 	ipsecSecretLength  = 48
 )
 
@@ -49,7 +49,7 @@ func newIPSECPSKSecret() (*v1.Secret, error) {
 
 	pskSecret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: ipsecPSKSecretName,
+			Name: IPSecPSKSecretName,
 		},
 		Data: pskSecretData,
 	}
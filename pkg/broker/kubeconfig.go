@@ -0,0 +1,127 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/rbac"
+	"github.com/submariner-io/subctl/pkg/client"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/strings/slices"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InfoFromKubeConfig builds the broker information from the broker cluster's kubeconfig, using the given context (the
+// current one if empty). See InfoFromCluster for the information retrieved.
+func InfoFromKubeConfig(ctx context.Context, kubeConfigPath, contextName string) (*Info, error) {
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeConfigPath},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading the broker kubeconfig %q", kubeConfigPath)
+	}
+
+	clientProducer, err := client.NewProducerFromRestConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the broker clients")
+	}
+
+	return InfoFromCluster(ctx, restConfig, clientProducer)
+}
+
+// InfoFromCluster builds the broker information from the broker cluster itself: the API server URL, as written to
+// broker-info files (including any scheme, which is removed when deploying), the broker administrator token and CA, and
+// the components and custom domains from the Broker resource, which must be unique. The IPsec PSK is only ever stored in
+// broker-info files, not on the broker cluster, so it isn't included; it must be provided separately.
+func InfoFromCluster(ctx context.Context, restConfig *rest.Config, clientProducer client.Producer) (*Info, error) {
+	brokers := &operatorv1alpha1.BrokerList{}
+
+	err := clientProducer.ForGeneral().List(ctx, brokers, controllerClient.InNamespace(""))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the Broker resources")
+	}
+
+	if len(brokers.Items) == 0 {
+		return nil, errors.New("no Broker resource was found, the broker doesn't appear to be deployed on this cluster")
+	}
+
+	if len(brokers.Items) > 1 {
+		namespaces := make([]string, len(brokers.Items))
+		for i := range brokers.Items {
+			namespaces[i] = brokers.Items[i].Namespace
+		}
+
+		return nil, fmt.Errorf("several Broker resources were found, in namespaces %s; unable to determine which one to use",
+			strings.Join(namespaces, ", "))
+	}
+
+	brokerCR := &brokers.Items[0]
+
+	clientToken, err := rbac.GetClientTokenSecret(ctx, clientProducer.ForKubernetes(), brokerCR.Namespace,
+		constants.SubmarinerBrokerAdminSA)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting the broker client secret")
+	}
+
+	if len(clientToken.Data["ca.crt"]) == 0 {
+		caData, err := caDataFrom(restConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		clientToken = clientToken.DeepCopy()
+		if clientToken.Data == nil {
+			clientToken.Data = map[string][]byte{}
+		}
+
+		clientToken.Data["ca.crt"] = caData
+	}
+
+	info := &Info{
+		BrokerURL:        restConfig.Host + restConfig.APIPath,
+		ClientToken:      clientToken,
+		ServiceDiscovery: slices.Contains(brokerCR.Spec.Components, component.ServiceDiscovery),
+		Components:       brokerCR.Spec.Components,
+	}
+
+	if len(brokerCR.Spec.DefaultCustomDomains) > 0 {
+		info.CustomDomains = &brokerCR.Spec.DefaultCustomDomains
+	}
+
+	return info, nil
+}
+
+func caDataFrom(restConfig *rest.Config) ([]byte, error) {
+	if len(restConfig.CAData) > 0 || restConfig.CAFile == "" {
+		return restConfig.CAData, nil
+	}
+
+	caData, err := os.ReadFile(restConfig.CAFile)
+
+	return caData, errors.Wrapf(err, "error reading the broker CA file %q", restConfig.CAFile)
+}
@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/client"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("InfoFromCluster", func() {
+	const brokerNamespace = "submariner-k8s-broker"
+
+	var (
+		brokerObjects []runtime.Object
+		restConfig    *rest.Config
+	)
+
+	BeforeEach(func() {
+		restConfig = &rest.Config{Host: "https://broker.example.com:6443", TLSClientConfig: rest.TLSClientConfig{
+			CAData: []byte("kubeconfig-ca"),
+		}}
+
+		brokerObjects = []runtime.Object{
+			&v1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Namespace: brokerNamespace, Name: constants.SubmarinerBrokerAdminSA},
+				Secrets:    []v1.ObjectReference{{Name: constants.SubmarinerBrokerAdminSA + "-token-abcde"}},
+			},
+			&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: brokerNamespace, Name: constants.SubmarinerBrokerAdminSA + "-token-abcde"},
+				Data:       map[string][]byte{"token": []byte("token"), "namespace": []byte(brokerNamespace)},
+			},
+		}
+	})
+
+	infoFromCluster := func(brokers ...*operatorv1alpha1.Broker) (*broker.Info, error) {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		builder := fakeClient.NewClientBuilder().WithScheme(scheme)
+		for _, brokerCR := range brokers {
+			builder = builder.WithObjects(brokerCR)
+		}
+
+		return broker.InfoFromCluster(context.TODO(), restConfig, &client.DefaultProducer{
+			KubeClient:    fake.NewSimpleClientset(brokerObjects...),
+			GeneralClient: builder.Build(),
+		})
+	}
+
+	When("the broker is deployed", func() {
+		It("should return its information", func() {
+			info, err := infoFromCluster(&operatorv1alpha1.Broker{
+				ObjectMeta: metav1.ObjectMeta{Namespace: brokerNamespace, Name: "submariner-broker"},
+				Spec: operatorv1alpha1.BrokerSpec{
+					Components:           []string{"connectivity", "service-discovery"},
+					DefaultCustomDomains: []string{"example.org"},
+				},
+			})
+			Expect(err).To(Succeed())
+			Expect(info.BrokerURL).To(Equal(restConfig.Host))
+			Expect(info.ClientToken.Data["ca.crt"]).To(Equal([]byte("kubeconfig-ca")))
+			Expect(info.IsConnectivityEnabled()).To(BeTrue())
+			Expect(info.IsServiceDiscoveryEnabled()).To(BeTrue())
+			Expect(*info.CustomDomains).To(Equal([]string{"example.org"}))
+			Expect(info.IPSecPSK).To(BeNil())
+		})
+	})
+
+	When("several brokers are deployed", func() {
+		It("should return an error listing their namespaces", func() {
+			_, err := infoFromCluster(
+				&operatorv1alpha1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: brokerNamespace, Name: "submariner-broker"}},
+				&operatorv1alpha1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: "other-broker", Name: "submariner-broker"}})
+			Expect(err).To(MatchError(ContainSubstring(brokerNamespace)))
+			Expect(err).To(MatchError(ContainSubstring("other-broker")))
+		})
+	})
+
+	When("the broker isn't deployed", func() {
+		It("should return an error", func() {
+			_, err := infoFromCluster()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,76 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// ProxyFunc determines the proxy to use for a request, as in rest.Config.
+type ProxyFunc func(*http.Request) (*url.URL, error)
+
+// NewProxyFunc returns a ProxyFunc using the given proxies for HTTP and HTTPS requests respectively; requests with a
+// scheme whose proxy isn't set go direct. If neither is set, the environment's proxy settings apply.
+func NewProxyFunc(httpProxy, httpsProxy string) (ProxyFunc, error) {
+	if httpProxy == "" && httpsProxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxies := map[string]*url.URL{}
+
+	for scheme, proxy := range map[string]string{"http": httpProxy, "https": httpsProxy} {
+		if proxy == "" {
+			continue
+		}
+
+		proxyURL, err := parseProxyURL(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		proxies[scheme] = proxyURL
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxies[req.URL.Scheme], nil
+	}, nil
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy URL %q", proxy)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q, the scheme must be http, https or socks5", proxy)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q, the host is missing", proxy)
+	}
+
+	return proxyURL, nil
+}
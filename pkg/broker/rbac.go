@@ -54,11 +54,21 @@ func NewBrokerAdminRole() *rbacv1.Role {
 				APIGroups: []string{"submariner.io"},
 				Resources: []string{"clusters", "endpoints"},
 			},
+			{
+				Verbs:     []string{"get", "list"},
+				APIGroups: []string{"submariner.io"},
+				Resources: []string{"brokers"},
+			},
 			{
 				Verbs:     []string{"create", "get", "list", "update", "delete", "watch"},
 				APIGroups: []string{""},
 				Resources: []string{"serviceaccounts", "secrets", "configmaps"},
 			},
+			{
+				Verbs:     []string{"create"},
+				APIGroups: []string{""},
+				Resources: []string{"serviceaccounts/token"},
+			},
 			{
 				Verbs:     []string{"create", "get", "list", "delete"},
 				APIGroups: []string{"rbac.authorization.k8s.io"},
@@ -33,11 +33,14 @@ const (
 	Name = "submariner-broker"
 )
 
-func Ensure(ctx context.Context, client controllerClient.Client, namespace string, brokerSpec submariner.BrokerSpec) error {
+func Ensure(ctx context.Context, client controllerClient.Client, namespace string, brokerSpec submariner.BrokerSpec,
+	annotations map[string]string,
+) error {
 	brokerCR := &submariner.Broker{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      Name,
-			Namespace: namespace,
+			Name:        Name,
+			Namespace:   namespace,
+			Annotations: annotations,
 		},
 		Spec: brokerSpec,
 	}
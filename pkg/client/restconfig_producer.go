@@ -20,6 +20,7 @@ package client
 
 import (
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -27,6 +28,13 @@ import (
 )
 
 func NewProducerFromRestConfig(config *rest.Config) (Producer, error) {
+	return NewProducerFromRestConfigWithScheme(config, nil)
+}
+
+// NewProducerFromRestConfigWithScheme creates a Producer whose general client uses the given scheme, rather than the
+// global client-go scheme, to map and convert types. This allows embedding subctl in controllers which maintain their own
+// scheme registrations. A nil scheme selects the global scheme.
+func NewProducerFromRestConfigWithScheme(config *rest.Config, scheme *runtime.Scheme) (Producer, error) {
 	var err error
 	p := &DefaultProducer{}
 
@@ -40,7 +48,7 @@ func NewProducerFromRestConfig(config *rest.Config) (Producer, error) {
 		return nil, errors.Wrap(err, "error creating dynamic client")
 	}
 
-	p.GeneralClient, err = client.New(config, client.Options{})
+	p.GeneralClient, err = client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating controller client")
 	}
@@ -19,18 +19,24 @@ limitations under the License.
 package cleanup
 
 import (
+	"context"
+
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/subctl/pkg/cloud/generic"
 	"github.com/submariner-io/subctl/pkg/cluster"
 )
 
-func GenericCluster(clusterInfo *cluster.Info, status reporter.Interface) error {
+func GenericCluster(ctx context.Context, clusterInfo *cluster.Info, reportOnly bool, status reporter.Interface) error {
 	defer status.End()
-	err := generic.RunOnCluster(clusterInfo, status,
+	err := generic.RunOnCluster(ctx, clusterInfo, reportOnly, status,
 		func(gwDeployer api.GatewayDeployer, status reporter.Interface) error {
 			return gwDeployer.Cleanup(status) //nolint:wrapcheck // No need to wrap here
 		})
 
+	if ctx.Err() != nil {
+		return err //nolint:wrapcheck // The cancellation has already been reported.
+	}
+
 	return status.Error(err, "Failed to cleanup generic K8s cluster")
 }
@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// auditingDeployer is a gateway deployer which cleans up node by node, so that it can report which nodes were changed,
+// including when the cleanup only partially succeeds or is cancelled.
+type auditingDeployer struct {
+	api.GatewayDeployer
+	ctx       context.Context
+	clientSet kubernetes.Interface
+}
+
+func (d *auditingDeployer) Cleanup(status reporter.Interface) error {
+	gwNodes, err := d.clientSet.CoreV1().Nodes().List(d.ctx, metav1.ListOptions{LabelSelector: k8s.SubmarinerGatewayLabel})
+	if d.ctx.Err() != nil {
+		return status.Error(d.ctx.Err(), "Cleanup cancelled before any node was changed")
+	}
+
+	if err != nil {
+		return status.Error(err, "error listing the gateway nodes")
+	}
+
+	if len(gwNodes.Items) == 0 {
+		status.Success("No node has the Submariner gateway label, the gateway nodes are already cleaned up")
+		return nil
+	}
+
+	var cleaned []string
+	var errs []error
+
+	for i := range gwNodes.Items {
+		if d.ctx.Err() != nil {
+			break
+		}
+
+		err := d.removeGatewayLabel(gwNodes.Items[i].Name)
+		if apierrors.IsNotFound(err) {
+			// The node was removed since it was listed, so there's nothing left to clean up
+			continue
+		}
+
+		if err != nil && d.ctx.Err() == nil {
+			errs = append(errs, fmt.Errorf("error removing the gateway label from node %q: %w", gwNodes.Items[i].Name, err))
+			continue
+		}
+
+		if err == nil {
+			cleaned = append(cleaned, gwNodes.Items[i].Name)
+		}
+	}
+
+	if len(cleaned) > 0 {
+		status.Success("Removed the Submariner gateway label from %d node(s): %s", len(cleaned), strings.Join(cleaned, ", "))
+	}
+
+	if d.ctx.Err() != nil {
+		return status.Error(d.ctx.Err(), "Cleanup cancelled, %d of %d node(s) cleaned up", len(cleaned), len(gwNodes.Items))
+	}
+
+	return status.Error(k8serrors.NewAggregate(errs), "Failed to clean up %d node(s)", len(errs))
+}
+
+func (d *auditingDeployer) removeGatewayLabel(nodeName string) error {
+	//nolint:wrapcheck // No need to wrap errors here.
+	err := util.MustUpdate(d.ctx, &resource.InterfaceFuncs{
+		GetFunc: func(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error) {
+			return d.clientSet.CoreV1().Nodes().Get(ctx, name, options)
+		},
+		UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+			return d.clientSet.CoreV1().Nodes().Update(ctx, obj.(*v1.Node), options)
+		},
+	}, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}, func(existing runtime.Object) (runtime.Object, error) {
+		delete(existing.(*v1.Node).Labels, k8s.SubmarinerGatewayLabel)
+		return existing, nil
+	})
+
+	return errors.Wrap(err, "error updating node")
+}
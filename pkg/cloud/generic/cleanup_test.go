@@ -0,0 +1,157 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/cloud/generic"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newNode(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func gatewayNodeNames(kubeClient *fake.Clientset) []string {
+	nodes, err := kubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: k8s.SubmarinerGatewayLabel})
+	Expect(err).To(Succeed())
+
+	names := []string{}
+	for i := range nodes.Items {
+		names = append(names, nodes.Items[i].Name)
+	}
+
+	return names
+}
+
+var _ = Describe("Cleanup", func() {
+	var (
+		kubeClient *fake.Clientset
+		recorder   *cli.Recorder
+		ctx        context.Context
+		reportOnly bool
+	)
+
+	BeforeEach(func() {
+		gatewayLabels := map[string]string{k8s.SubmarinerGatewayLabel: "true"}
+		kubeClient = fake.NewSimpleClientset(newNode("node-1", gatewayLabels), newNode("node-2", gatewayLabels),
+			newNode("node-3", nil))
+		recorder = cli.NewRecorder(reporter.Silent())
+		ctx = context.TODO()
+		reportOnly = false
+	})
+
+	cleanup := func() error {
+		return generic.RunOnCluster(ctx, &cluster.Info{Name: "east", ClientProducer: &client.DefaultProducer{KubeClient: kubeClient}},
+			reportOnly, recorder.Reporter(), func(gwDeployer api.GatewayDeployer, status reporter.Interface) error {
+				return gwDeployer.Cleanup(status)
+			})
+	}
+
+	messages := func() []string {
+		messages := []string{}
+		for _, result := range recorder.Results() {
+			messages = append(messages, result.Messages...)
+		}
+
+		return messages
+	}
+
+	It("should remove the gateway label and report the nodes changed", func() {
+		Expect(cleanup()).To(Succeed())
+		Expect(gatewayNodeNames(kubeClient)).To(BeEmpty())
+		Expect(messages()).To(ConsistOf("Removed the Submariner gateway label from 2 node(s): node-1, node-2"))
+	})
+
+	It("should succeed when re-run", func() {
+		Expect(cleanup()).To(Succeed())
+		Expect(cleanup()).To(Succeed())
+		Expect(messages()).To(ContainElement(ContainSubstring("already cleaned up")))
+		Expect(recorder.Failed()).To(BeFalse())
+	})
+
+	When("a node is removed during the cleanup", func() {
+		It("should skip it", func() {
+			kubeClient.PrependReactor("get", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.(k8stesting.GetAction).GetName() == "node-1" {
+					return true, nil, apierrors.NewNotFound(corev1.Resource("nodes"), "node-1")
+				}
+
+				return false, nil, nil
+			})
+
+			Expect(cleanup()).To(Succeed())
+			Expect(messages()).To(ConsistOf("Removed the Submariner gateway label from 1 node(s): node-2"))
+		})
+	})
+
+	When("a node fails to be updated", func() {
+		It("should report the nodes changed and return an error", func() {
+			kubeClient.PrependReactor("update", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.(k8stesting.UpdateAction).GetObject().(*corev1.Node).Name == "node-2" {
+					return true, nil, errors.New("fake error")
+				}
+
+				return false, nil, nil
+			})
+
+			Expect(cleanup()).ToNot(Succeed())
+			Expect(gatewayNodeNames(kubeClient)).To(Equal([]string{"node-2"}))
+			Expect(messages()).To(ContainElement("Removed the Submariner gateway label from 1 node(s): node-1"))
+		})
+	})
+
+	When("the context is cancelled", func() {
+		It("should not change any node", func() {
+			cancelled, cancel := context.WithCancel(context.TODO())
+			cancel()
+
+			ctx = cancelled
+
+			Expect(cleanup()).To(MatchError(context.Canceled))
+			Expect(gatewayNodeNames(kubeClient)).To(ConsistOf("node-1", "node-2"))
+		})
+	})
+
+	When("only reporting", func() {
+		It("should report the nodes which would be changed without changing them", func() {
+			reportOnly = true
+
+			Expect(cleanup()).To(Succeed())
+			Expect(gatewayNodeNames(kubeClient)).To(ConsistOf("node-1", "node-2"))
+			Expect(messages()).To(ConsistOf(`Would remove the Submariner gateway label from node "node-1"`,
+				`Would remove the Submariner gateway label from node "node-2"`))
+		})
+	})
+})
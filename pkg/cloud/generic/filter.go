@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// controlPlaneLabels identify control plane nodes, which are only labeled as gateways if no other node matches the filter.
+var controlPlaneLabels = []string{"node-role.kubernetes.io/control-plane", "node-role.kubernetes.io/master"}
+
+// LabelGatewayNodes labels up to the given number of gateway nodes, only choosing among the nodes matching the given
+// label selector. Matching nodes which are already labeled as gateways count towards the requested number. Control plane
+// nodes are only labeled if no other node matches the selector. An error is returned if no node matches the selector.
+func LabelGatewayNodes(clusterInfo *cluster.Info, nodeSelector string, gateways int, status reporter.Interface) error {
+	_, err := labels.Parse(nodeSelector)
+	if err != nil {
+		return errors.Wrapf(err, "invalid gateway node filter %q", nodeSelector)
+	}
+
+	k8sClient := k8s.NewInterface(clusterInfo.ClientProducer.ForKubernetes())
+
+	nodes, err := k8sClient.ListNodesWithLabel(nodeSelector)
+	if err != nil {
+		return errors.Wrap(err, "error listing the nodes matching the gateway node filter")
+	}
+
+	if len(nodes.Items) == 0 {
+		return fmt.Errorf("no node matches the gateway node filter %q", nodeSelector)
+	}
+
+	onlyControlPlane := true
+
+	for i := range nodes.Items {
+		if !isControlPlane(&nodes.Items[i]) {
+			onlyControlPlane = false
+			break
+		}
+	}
+
+	if onlyControlPlane {
+		status.Warning("Only control plane nodes match the gateway node filter %q, they will be used as gateways", nodeSelector)
+	}
+
+	labeled := 0
+	toLabel := []string{}
+
+	for i := range nodes.Items {
+		if nodes.Items[i].Labels[k8s.SubmarinerGatewayLabel] == "true" {
+			labeled++
+		} else if onlyControlPlane || !isControlPlane(&nodes.Items[i]) {
+			toLabel = append(toLabel, nodes.Items[i].Name)
+		}
+	}
+
+	alreadyLabeled := labeled
+
+	for _, name := range toLabel {
+		if labeled >= gateways {
+			break
+		}
+
+		err = k8sClient.AddGWLabelOnNode(name)
+		if err != nil {
+			return errors.Wrapf(err, "error labeling node %q as a gateway", name)
+		}
+
+		labeled++
+	}
+
+	status.Success("%d node(s) match the gateway node filter %q, %d labeled as gateways (%d already were)", len(nodes.Items),
+		nodeSelector, labeled, alreadyLabeled)
+
+	if labeled < gateways {
+		status.Warning("Only %d node(s) matching the gateway node filter can be used as gateways, fewer than the %d requested",
+			labeled, gateways)
+	}
+
+	return nil
+}
+
+func isControlPlane(node *corev1.Node) bool {
+	for _, label := range controlPlaneLabels {
+		if _, ok := node.Labels[label]; ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/cloud/generic"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("LabelGatewayNodes", func() {
+	var kubeClient *fake.Clientset
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset(
+			newNode("edge-1", map[string]string{"zone": "edge", k8s.SubmarinerGatewayLabel: "true"}),
+			newNode("edge-2", map[string]string{"zone": "edge"}),
+			newNode("edge-3", map[string]string{"zone": "edge"}),
+			newNode("core-1", map[string]string{"zone": "core"}),
+			newNode("control-1", map[string]string{"zone": "edge", "node-role.kubernetes.io/control-plane": ""}),
+			newNode("control-2", map[string]string{"zone": "control", "node-role.kubernetes.io/control-plane": ""}),
+			newNode("control-3", map[string]string{"zone": "control", "node-role.kubernetes.io/master": ""}))
+	})
+
+	DescribeTable("labeling the nodes matching the filter",
+		func(nodeSelector string, gateways int, expectedGateways []string, expectedStatus string) {
+			recorder := cli.NewRecorder(reporter.Silent())
+
+			Expect(generic.LabelGatewayNodes(&cluster.Info{ClientProducer: &client.DefaultProducer{KubeClient: kubeClient}},
+				nodeSelector, gateways, recorder.Reporter())).To(Succeed())
+			Expect(gatewayNodeNames(kubeClient)).To(ConsistOf(expectedGateways))
+			results := recorder.Results()
+			Expect(results).ToNot(BeEmpty())
+			Expect(results[len(results)-1].Status).To(Equal(expectedStatus))
+		},
+		Entry("counting the nodes already labeled", "zone=edge", 2, []string{"edge-1", "edge-2"}, cli.CheckPassed),
+		Entry("with enough labeled nodes", "zone=edge", 1, []string{"edge-1"}, cli.CheckPassed),
+		Entry("with fewer matching nodes than requested", "zone=edge", 4, []string{"edge-1", "edge-2", "edge-3"},
+			cli.CheckWarning),
+		Entry("only among the matching nodes", "zone=core", 1, []string{"edge-1", "core-1"}, cli.CheckPassed),
+		Entry("skipping the control plane nodes", "zone=edge", 3, []string{"edge-1", "edge-2", "edge-3"}, cli.CheckPassed),
+		Entry("skipping the control plane nodes even if fewer nodes remain", "zone=edge", 5,
+			[]string{"edge-1", "edge-2", "edge-3"}, cli.CheckWarning),
+	)
+
+	When("only control plane nodes match the filter", func() {
+		It("should label them with a warning", func() {
+			recorder := cli.NewRecorder(reporter.Silent())
+
+			Expect(generic.LabelGatewayNodes(&cluster.Info{ClientProducer: &client.DefaultProducer{KubeClient: kubeClient}},
+				"zone=control", 2, recorder.Reporter())).To(Succeed())
+			Expect(gatewayNodeNames(kubeClient)).To(ConsistOf("edge-1", "control-2", "control-3"))
+			Expect(recorder.Results()).To(ContainElement(HaveField("Status", cli.CheckWarning)))
+		})
+	})
+
+	DescribeTable("invalid filters",
+		func(nodeSelector string) {
+			Expect(generic.LabelGatewayNodes(&cluster.Info{ClientProducer: &client.DefaultProducer{KubeClient: kubeClient}},
+				nodeSelector, 1, reporter.Silent())).ToNot(Succeed())
+			Expect(gatewayNodeNames(kubeClient)).To(ConsistOf("edge-1"))
+		},
+		Entry("an unparsable selector", "zone in (edge"),
+		Entry("a selector matching no node", "zone=cloud"),
+	)
+})
@@ -19,6 +19,8 @@ limitations under the License.
 package generic
 
 import (
+	"context"
+
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/cloud-prepare/pkg/generic"
@@ -26,12 +28,24 @@ import (
 	"github.com/submariner-io/subctl/pkg/cluster"
 )
 
-func RunOnCluster(clusterInfo *cluster.Info, status reporter.Interface,
+// RunOnCluster runs the given function with a generic gateway deployer for the cluster. In report-only mode, the deployer
+// only reports the changes it would make to the cluster, without making them. The deployer's cleanup stops when the given
+// context is cancelled.
+func RunOnCluster(ctx context.Context, clusterInfo *cluster.Info, reportOnly bool, status reporter.Interface,
 	function func(api.GatewayDeployer, reporter.Interface) error,
 ) error {
 	clientSet := clusterInfo.ClientProducer.ForKubernetes()
 	k8sClientSet := k8s.NewInterface(clientSet)
-	gwDeployer := generic.NewGatewayDeployer(k8sClientSet)
+
+	if reportOnly {
+		return function(&reportingDeployer{k8sClient: k8sClientSet}, status)
+	}
+
+	gwDeployer := &auditingDeployer{
+		GatewayDeployer: generic.NewGatewayDeployer(k8sClientSet),
+		ctx:             ctx,
+		clientSet:       clientSet,
+	}
 
 	return function(gwDeployer, status)
 }
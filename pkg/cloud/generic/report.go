@@ -0,0 +1,64 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+)
+
+// reportingDeployer is a gateway deployer which reports the changes the generic gateway deployer would make, without
+// making them.
+type reportingDeployer struct {
+	k8sClient k8s.Interface
+}
+
+func (d *reportingDeployer) Deploy(input api.GatewayDeployInput, status reporter.Interface) error {
+	gwNodes, err := d.k8sClient.ListGatewayNodes()
+	if err != nil {
+		return status.Error(err, "error listing the gateway nodes")
+	}
+
+	if missing := input.Gateways - len(gwNodes.Items); missing > 0 {
+		status.Success("Would label %d more worker node(s) as gateways, %d are currently labeled", missing, len(gwNodes.Items))
+	} else {
+		status.Success("The requested number of gateway nodes are already labeled, nothing would be changed")
+	}
+
+	return nil
+}
+
+func (d *reportingDeployer) Cleanup(status reporter.Interface) error {
+	gwNodes, err := d.k8sClient.ListNodesWithLabel(k8s.SubmarinerGatewayLabel)
+	if err != nil {
+		return status.Error(err, "error listing the gateway nodes")
+	}
+
+	if len(gwNodes.Items) == 0 {
+		status.Success("No node has the Submariner gateway label, nothing would be removed")
+		return nil
+	}
+
+	for i := range gwNodes.Items {
+		status.Success("Would remove the Submariner gateway label from node %q", gwNodes.Items[i].Name)
+	}
+
+	return nil
+}
@@ -19,17 +19,32 @@ limitations under the License.
 package prepare
 
 import (
+	"context"
+	"strings"
+
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/subctl/pkg/cloud/generic"
 	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/deploy"
 )
 
-func GenericCluster(clusterInfo *cluster.Info, gateways int, status reporter.Interface) error {
+// GenericCluster labels the requested number of gateway nodes. If a node filter is given, only the nodes matching that
+// label selector are considered.
+func GenericCluster(clusterInfo *cluster.Info, gateways int, nodeFilter string, status reporter.Interface) error {
 	defer status.End()
 
+	if nodeFilter != "" && gateways > 0 {
+		err := generic.LabelGatewayNodes(clusterInfo, nodeFilter, gateways, status)
+		if err == nil {
+			warnOnExtraGateways(clusterInfo, gateways, status)
+		}
+
+		return status.Error(err, "Failed to prepare generic K8s cluster")
+	}
+
 	//nolint:wrapcheck // No need to wrap errors here.
-	err := generic.RunOnCluster(clusterInfo, status,
+	err := generic.RunOnCluster(context.TODO(), clusterInfo, false, status,
 		func(gwDeployer api.GatewayDeployer, status reporter.Interface) error {
 			if gateways > 0 {
 				gwInput := api.GatewayDeployInput{
@@ -39,6 +54,8 @@ func GenericCluster(clusterInfo *cluster.Info, gateways int, status reporter.Int
 				if err != nil {
 					return err
 				}
+
+				warnOnExtraGateways(clusterInfo, gateways, status)
 			}
 
 			return nil
@@ -46,3 +63,16 @@ func GenericCluster(clusterInfo *cluster.Info, gateways int, status reporter.Int
 
 	return status.Error(err, "Failed to prepare generic K8s cluster")
 }
+
+func warnOnExtraGateways(clusterInfo *cluster.Info, gateways int, status reporter.Interface) {
+	count, nodeNames, err := deploy.CountGatewayNodes(context.TODO(), clusterInfo.ClientProducer.ForKubernetes())
+	if err != nil {
+		status.Warning("Unable to count the gateway nodes: %s", err)
+		return
+	}
+
+	if count > gateways {
+		status.Warning("%d nodes are labeled as gateways, more than the %d requested: %s", count, gateways,
+			strings.Join(nodeNames, ", "))
+	}
+}
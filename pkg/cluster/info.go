@@ -200,6 +200,11 @@ var validOverrides = []string{
 	names.NettestComponent,
 }
 
+// ImageOverrideComponents returns the names of the components whose images can be overridden.
+func ImageOverrideComponents() []string {
+	return append([]string{}, validOverrides...)
+}
+
 func MergeImageOverrides(imageOverrides map[string]string, localImageOverrides []string) (map[string]string, error) {
 	if imageOverrides == nil {
 		imageOverrides = make(map[string]string, len(localImageOverrides))
@@ -0,0 +1,51 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/cluster"
+)
+
+var _ = Describe("MergeImageOverrides", func() {
+	It("should only override the given components", func() {
+		Expect(cluster.MergeImageOverrides(map[string]string{"submariner-gateway": "gw:v1"},
+			[]string{"submariner-routeagent=quay.io/example/routeagent:fix"})).To(Equal(map[string]string{
+			"submariner-gateway":    "gw:v1",
+			"submariner-routeagent": "quay.io/example/routeagent:fix",
+		}))
+	})
+
+	It("should reject unknown components", func() {
+		_, err := cluster.MergeImageOverrides(nil, []string{"submariner-unknown=example/unknown:v1"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject overrides without a component", func() {
+		_, err := cluster.MergeImageOverrides(nil, []string{"example/routeagent:fix"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ImageOverrideComponents", func() {
+	It("should list the valid components", func() {
+		Expect(cluster.ImageOverrideComponents()).To(ContainElements("submariner-operator", "submariner-routeagent"))
+	})
+})
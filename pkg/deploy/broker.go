@@ -103,7 +103,8 @@ func deploy(ctx context.Context, options *BrokerOptions, status reporter.Interfa
 
 	status.Start("Deploying the broker")
 
-	err = brokercr.Ensure(ctx, clientProducer.ForGeneral(), options.BrokerNamespace, options.BrokerSpec)
+	err = brokercr.Ensure(ctx, clientProducer.ForGeneral(), options.BrokerNamespace, options.BrokerSpec,
+		map[string]string{constants.SubmarinerVersionAnnotation: repositoryInfo.Version})
 
 	return status.Error(err, "Broker deployment failed")
 }
@@ -0,0 +1,96 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/image"
+)
+
+const redactedAPIServer = "##redacted-api-server##"
+
+type deployInputs struct {
+	Options    SubmarinerOptions `json:"options"`
+	Broker     brokerMetadata    `json:"broker"`
+	Repository repositoryInputs  `json:"repository"`
+}
+
+type brokerMetadata struct {
+	ServiceDiscovery bool     `json:"serviceDiscovery"`
+	HasClientToken   bool     `json:"hasClientToken"`
+	HasIPSecPSK      bool     `json:"hasIPsecPSK"`
+	BrokerURL        string   `json:"brokerURL"`
+	Namespace        string   `json:"namespace,omitempty"`
+	Components       []string `json:"components,omitempty"`
+	CustomDomains    []string `json:"customDomains,omitempty"`
+}
+
+type repositoryInputs struct {
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	ResolvedVersion string `json:"resolvedVersion,omitempty"`
+	ResolutionError string `json:"resolutionError,omitempty"`
+}
+
+// DeployInputsBundle returns a JSON document describing everything which influences a Submariner deployment with the given
+// parameters, suitable for attaching to bug reports: the options, the broker metadata and the resolved repository. The
+// broker token, the IPsec PSK and the broker API server are never included.
+func DeployInputsBundle(options *SubmarinerOptions, brokerInfo *broker.Info) ([]byte, error) {
+	inputs := deployInputs{
+		Options: *options,
+		Broker: brokerMetadata{
+			ServiceDiscovery: brokerInfo.IsServiceDiscoveryEnabled(),
+			HasIPSecPSK:      brokerInfo.IPSecPSK != nil && len(brokerInfo.IPSecPSK.Data["psk"]) > 0,
+			Components:       brokerInfo.Components,
+		},
+	}
+
+	if brokerInfo.BrokerURL != "" {
+		inputs.Broker.BrokerURL = redactedAPIServer
+	}
+
+	if brokerInfo.ClientToken != nil {
+		inputs.Broker.HasClientToken = len(brokerInfo.ClientToken.Data["token"]) > 0
+		inputs.Broker.Namespace = string(brokerInfo.ClientToken.Data["namespace"])
+	}
+
+	if brokerInfo.CustomDomains != nil {
+		inputs.Broker.CustomDomains = *brokerInfo.CustomDomains
+	}
+
+	repositoryInfo := image.NewRepositoryInfo(options.Repository, options.ImageVersion, nil)
+	inputs.Repository = repositoryInputs{
+		Name:    repositoryInfo.Name,
+		Version: repositoryInfo.Version,
+	}
+
+	resolvedVersion, err := resolveVersion(repositoryInfo.Version, options.VersionResolver)
+	if err != nil {
+		inputs.Repository.ResolutionError = err.Error()
+	} else {
+		inputs.Repository.ResolvedVersion = resolvedVersion
+	}
+
+	bundle, err := json.MarshalIndent(&inputs, "", "  ")
+
+	return bundle, errors.Wrap(err, "error marshalling the deployment inputs")
+}
@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/deploy"
+)
+
+var _ = Describe("DeployInputsBundle", func() {
+	var bundle string
+
+	BeforeEach(func() {
+		options := newTestSubmarinerOptions()
+		options.ImageVersion = "stable"
+		options.VersionResolver = deploy.ChannelMap{"stable": "0.15.0"}.Resolve
+
+		brokerInfo, _ := newTestBrokerInfo()
+
+		output, err := deploy.DeployInputsBundle(options, brokerInfo)
+		Expect(err).To(Succeed())
+		Expect(json.Valid(output)).To(BeTrue())
+
+		bundle = string(output)
+	})
+
+	It("should not include the broker credentials", func() {
+		Expect(bundle).ToNot(ContainSubstring(testToken))
+		Expect(bundle).ToNot(ContainSubstring(testPSK))
+		Expect(bundle).ToNot(ContainSubstring("broker.example.com"))
+		Expect(bundle).To(ContainSubstring(`"hasClientToken": true`))
+	})
+
+	It("should include the options and the resolved version", func() {
+		Expect(bundle).To(ContainSubstring(`"ClusterID": "east"`))
+		Expect(bundle).To(ContainSubstring(`"resolvedVersion": "0.15.0"`))
+	})
+})
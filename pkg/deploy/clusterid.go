@@ -0,0 +1,51 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const generatedClusterIDHashLength = 12
+
+// GenerateClusterID derives a cluster ID from the UID of the cluster's kube-system namespace. The ID is stable for a given
+// cluster, and is a valid DNS-1123 label.
+func GenerateClusterID(ctx context.Context, client controllerClient.Client) (string, error) {
+	namespace := &v1.Namespace{}
+
+	err := client.Get(ctx, controllerClient.ObjectKey{Name: metav1.NamespaceSystem}, namespace)
+	if err != nil {
+		return "", errors.Wrap(err, "error retrieving the kube-system namespace")
+	}
+
+	if namespace.UID == "" {
+		return "", errors.New("the kube-system namespace has no UID")
+	}
+
+	sum := sha256.Sum256([]byte(namespace.UID))
+
+	return "cluster-" + hex.EncodeToString(sum[:])[:generatedClusterIDHashLength], nil
+}
@@ -0,0 +1,53 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("GenerateClusterID", func() {
+	generate := func(uid types.UID) (string, error) {
+		return deploy.GenerateClusterID(context.TODO(), fakeClient.NewClientBuilder().WithObjects(&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: uid},
+		}).Build())
+	}
+
+	It("should generate a stable and valid cluster ID", func() {
+		clusterID, err := generate("6d1f4e8c-2b1a-4c8e-9a43-2f0d3c1b5e77")
+		Expect(err).To(Succeed())
+		Expect(validation.IsDNS1123Label(clusterID)).To(BeEmpty())
+		Expect(generate("6d1f4e8c-2b1a-4c8e-9a43-2f0d3c1b5e77")).To(Equal(clusterID))
+	})
+
+	It("should generate different cluster IDs for different clusters", func() {
+		clusterID, err := generate("6d1f4e8c-2b1a-4c8e-9a43-2f0d3c1b5e77")
+		Expect(err).To(Succeed())
+		Expect(generate("0b3a6c1e-7f2d-4b9a-8e15-9c4d2a7f6b30")).ToNot(Equal(clusterID))
+	})
+})
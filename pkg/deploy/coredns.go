@@ -0,0 +1,69 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CoreDNSRef references a custom CoreDNS config map.
+type CoreDNSRef struct {
+	Namespace string
+	Name      string
+}
+
+// ParseCoreDNSRef parses a custom CoreDNS config map reference, in "<namespace>/<name>" format where the namespace is
+// optional. When omitted, the namespace defaults to kube-system, as it does in the operator.
+func ParseCoreDNSRef(s string) (CoreDNSRef, error) {
+	ref := CoreDNSRef{Namespace: metav1.NamespaceSystem, Name: s}
+
+	parts := strings.Split(s, "/")
+
+	switch len(parts) {
+	case 1:
+	case 2:
+		ref.Namespace, ref.Name = parts[0], parts[1]
+	default:
+		return CoreDNSRef{}, fmt.Errorf("invalid CoreDNS config map reference %q, it should be in <namespace>/<name> format", s)
+	}
+
+	if errs := validation.IsDNS1123Label(ref.Namespace); len(errs) > 0 {
+		return CoreDNSRef{}, fmt.Errorf("invalid namespace %q in the CoreDNS config map reference %q: %s", ref.Namespace, s,
+			strings.Join(errs, ", "))
+	}
+
+	if errs := validation.IsDNS1123Subdomain(ref.Name); len(errs) > 0 {
+		return CoreDNSRef{}, fmt.Errorf("invalid name %q in the CoreDNS config map reference %q: %s", ref.Name, s,
+			strings.Join(errs, ", "))
+	}
+
+	return ref, nil
+}
+
+func (r CoreDNSRef) toCoreDNSCustomConfig() *operatorv1alpha1.CoreDNSCustomConfig {
+	return &operatorv1alpha1.CoreDNSCustomConfig{
+		ConfigMapName: r.Name,
+		Namespace:     r.Namespace,
+	}
+}
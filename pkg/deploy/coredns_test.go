@@ -0,0 +1,50 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/deploy"
+)
+
+var _ = Describe("ParseCoreDNSRef", func() {
+	When("the namespace is specified", func() {
+		It("should return it", func() {
+			Expect(deploy.ParseCoreDNSRef("dns/coredns-custom")).To(Equal(deploy.CoreDNSRef{Namespace: "dns", Name: "coredns-custom"}))
+		})
+	})
+
+	When("the namespace is omitted", func() {
+		It("should default to kube-system", func() {
+			Expect(deploy.ParseCoreDNSRef("coredns-custom")).To(Equal(deploy.CoreDNSRef{Namespace: "kube-system", Name: "coredns-custom"}))
+		})
+	})
+
+	DescribeTable("invalid references",
+		func(ref string) {
+			_, err := deploy.ParseCoreDNSRef(ref)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too many components", "a/b/c"),
+		Entry("an empty name", "dns/"),
+		Entry("an invalid namespace", "DNS_ns/coredns-custom"),
+		Entry("an invalid name", "coredns custom"),
+	)
+})
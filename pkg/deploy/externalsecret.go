@@ -0,0 +1,120 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/resource"
+	resourceutil "github.com/submariner-io/subctl/pkg/resource"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	secretStoreKind        = "SecretStore"
+	clusterSecretStoreKind = "ClusterSecretStore"
+)
+
+var externalSecretGVR = schema.GroupVersionResource{Group: "external-secrets.io", Version: "v1beta1", Resource: "externalsecrets"}
+
+// parseSecretStoreRef parses a secret store reference, in "[<kind>/]<name>" format where the kind is either SecretStore
+// (the default) or ClusterSecretStore.
+func parseSecretStoreRef(ref string) (kind, name string, err error) {
+	kind, name = secretStoreKind, ref
+
+	if storeKind, storeName, found := strings.Cut(ref, "/"); found {
+		kind, name = storeKind, storeName
+	}
+
+	if kind != secretStoreKind && kind != clusterSecretStoreKind {
+		return "", "", fmt.Errorf("invalid secret store kind %q in %q, it should be %s or %s", kind, ref, secretStoreKind,
+			clusterSecretStoreKind)
+	}
+
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", "", fmt.Errorf("invalid secret store name %q in %q: %s", name, ref, strings.Join(errs, ", "))
+	}
+
+	return kind, name, nil
+}
+
+func newPSKExternalSecret(options *SubmarinerOptions, namespace, secretName string) (*unstructured.Unstructured, error) {
+	kind, name, err := parseSecretStoreRef(options.PSKExternalSecretStore)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.PSKExternalSecretKey == "" {
+		return nil, errors.New("the key of the PSK in the external secret store must be specified")
+	}
+
+	externalSecret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"secretStoreRef": map[string]interface{}{
+				"kind": kind,
+				"name": name,
+			},
+			"target": map[string]interface{}{
+				"name": secretName,
+			},
+			"data": []interface{}{
+				map[string]interface{}{
+					"secretKey": "psk",
+					"remoteRef": map[string]interface{}{
+						"key": options.PSKExternalSecretKey,
+					},
+				},
+			},
+		},
+	}}
+
+	externalSecret.SetAPIVersion(externalSecretGVR.GroupVersion().String())
+	externalSecret.SetKind("ExternalSecret")
+	externalSecret.SetName(secretName)
+	externalSecret.SetNamespace(namespace)
+
+	return externalSecret, nil
+}
+
+// ensurePSKExternalSecret creates or updates an ExternalSecret producing the PSK secret, instead of creating the secret
+// from the broker information. The returned secret only carries the name of the secret which will be produced.
+func ensurePSKExternalSecret(ctx context.Context, dynamicClient dynamic.Interface, options *SubmarinerOptions, namespace,
+	secretName string,
+) (*v1.Secret, error) {
+	externalSecret, err := newPSKExternalSecret(options, namespace, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = resourceutil.CreateOrUpdate(ctx, resource.ForDynamic(dynamicClient.Resource(externalSecretGVR).Namespace(namespace)),
+		externalSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the PSK ExternalSecret")
+	}
+
+	return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}}, nil
+}
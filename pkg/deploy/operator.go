@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	submarinerCRDName          = "submariners.submariner.io"
+	operatorCheckInterval      = 2 * time.Second
+	defaultOperatorWaitTimeout = 5 * time.Minute
+)
+
+// awaitOperator waits for the Submariner CRD to be installed and the operator deployment to be available. On timeout,
+// the returned error lists whichever is still missing.
+func awaitOperator(ctx context.Context, clientProducer client.Producer, namespace string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultOperatorWaitTimeout
+	}
+
+	var missing []string
+
+	err := wait.PollImmediate(operatorCheckInterval, timeout, func() (bool, error) {
+		var err error
+
+		missing, err = missingOperatorPrerequisites(ctx, clientProducer, namespace)
+
+		return len(missing) == 0, err
+	})
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timed out after %v waiting for %s", timeout, strings.Join(missing, " and "))
+	}
+
+	return err //nolint:wrapcheck // No need to wrap errors here.
+}
+
+func missingOperatorPrerequisites(ctx context.Context, clientProducer client.Producer, namespace string) ([]string, error) {
+	missing := []string{}
+
+	err := clientProducer.ForGeneral().Get(ctx, controllerClient.ObjectKey{Name: submarinerCRDName},
+		&apiextensionsv1.CustomResourceDefinition{})
+	if apierrors.IsNotFound(err) {
+		missing = append(missing, fmt.Sprintf("the %q CRD", submarinerCRDName))
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving the %q CRD", submarinerCRDName)
+	}
+
+	operator, err := clientProducer.ForKubernetes().AppsV1().Deployments(namespace).Get(ctx,
+		names.OperatorComponent, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "error retrieving the operator deployment")
+	}
+
+	if err != nil || !isAvailable(operator) {
+		missing = append(missing, fmt.Sprintf("the %q deployment to be available", names.OperatorComponent))
+	}
+
+	return missing, nil
+}
+
+func isAvailable(deployment *appsv1.Deployment) bool {
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable && cond.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	"github.com/submariner-io/subctl/pkg/image"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Waiting for the operator", func() {
+	When("the operator isn't installed", func() {
+		It("should time out listing what is missing", func() {
+			scheme := runtime.NewScheme()
+			Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+
+			clientProducer := &client.DefaultProducer{
+				KubeClient:    fake.NewSimpleClientset(),
+				GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).Build(),
+			}
+
+			options := newTestSubmarinerOptions()
+			options.WaitForOperator = true
+			options.OperatorWaitTimeout = 10 * time.Millisecond
+			brokerInfo, brokerSecret := newTestBrokerInfo()
+
+			_, err := deploy.Submariner(context.TODO(), clientProducer, options, brokerInfo, brokerSecret, globalnet.Config{},
+				image.NewRepositoryInfo("", "", nil), reporter.Silent())
+			Expect(err).To(MatchError(ContainSubstring("submariners.submariner.io")))
+			Expect(err).To(MatchError(ContainSubstring("submariner-operator")))
+		})
+	})
+
+	When("the operator is available in a custom namespace", func() {
+		It("should look for it there", func() {
+			scheme := runtime.NewScheme()
+			Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+			Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "submariners.submariner.io"}}
+			operator := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: names.OperatorComponent, Namespace: "tenant-a"},
+				Status: appsv1.DeploymentStatus{
+					Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+				},
+			}
+
+			clientProducer := &client.DefaultProducer{
+				KubeClient:    fake.NewSimpleClientset(operator),
+				GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(crd).Build(),
+			}
+
+			options := newTestSubmarinerOptions()
+			options.Namespace = "tenant-a"
+			options.WaitForOperator = true
+			options.OperatorWaitTimeout = 10 * time.Millisecond
+			brokerInfo, brokerSecret := newTestBrokerInfo()
+
+			submariner, err := deploy.Submariner(context.TODO(), clientProducer, options, brokerInfo, brokerSecret, globalnet.Config{},
+				image.NewRepositoryInfo("", "", nil), reporter.Silent())
+			Expect(err).To(Succeed())
+			Expect(submariner.Namespace).To(Equal("tenant-a"))
+		})
+	})
+})
@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import "fmt"
+
+const (
+	defaultNATTPort      = 4500
+	ikePort              = 500
+	intraClusterVXLAN    = 4800
+	gatewayMetricsPort   = 8080
+	globalnetMetricsPort = 8081
+)
+
+// PortSpec describes network traffic which must be allowed for Submariner to work. Protocols which don't use ports, such
+// as ESP, have a zero port.
+type PortSpec struct {
+	Port     int
+	Protocol string
+	Purpose  string
+}
+
+// RequiredFirewallPorts returns the traffic which firewalls must allow for a deployment with these options, and with
+// globalnet if enabled: between gateways in different clusters, depending on the cable driver, and within the cluster,
+// between nodes and gateways. An unknown cable driver is an error, since its traffic can't be determined.
+func (o *SubmarinerOptions) RequiredFirewallPorts(globalnetEnabled bool) ([]PortSpec, error) {
+	nattPort := o.NATTPort
+	if nattPort == 0 {
+		nattPort = defaultNATTPort
+	}
+
+	ports := []PortSpec{}
+
+	switch o.CableDriver {
+	case "", defaultCableDriver:
+		ports = append(ports,
+			PortSpec{Port: ikePort, Protocol: "UDP", Purpose: "IPsec IKE between gateways"},
+			PortSpec{Port: nattPort, Protocol: "UDP", Purpose: "IPsec NAT traversal between gateways"})
+
+		if !o.ForceUDPEncaps {
+			ports = append(ports, PortSpec{Protocol: "ESP", Purpose: "IPsec ESP between gateways"})
+		}
+	case "wireguard":
+		ports = append(ports, PortSpec{Port: nattPort, Protocol: "UDP", Purpose: "WireGuard tunnels between gateways"})
+	case "vxlan":
+		ports = append(ports, PortSpec{Port: nattPort, Protocol: "UDP", Purpose: "VXLAN tunnels between gateways"})
+	default:
+		return nil, fmt.Errorf("unknown cable driver %q", o.CableDriver)
+	}
+
+	ports = append(ports,
+		PortSpec{Port: intraClusterVXLAN, Protocol: "UDP", Purpose: "VXLAN between the nodes and the gateway within the cluster"},
+		PortSpec{Port: gatewayMetricsPort, Protocol: "TCP", Purpose: "gateway metrics within the cluster"})
+
+	if globalnetEnabled {
+		ports = append(ports, PortSpec{Port: globalnetMetricsPort, Protocol: "TCP", Purpose: "globalnet metrics within the cluster"})
+	}
+
+	if o.HealthCheckEnabled {
+		ports = append(ports, PortSpec{Protocol: "ICMP", Purpose: "gateway health checks through the tunnels"})
+	}
+
+	return ports, nil
+}
@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/deploy"
+)
+
+var _ = Describe("RequiredFirewallPorts", func() {
+	portsOf := func(options *deploy.SubmarinerOptions, globalnetEnabled bool) []string {
+		specs, err := options.RequiredFirewallPorts(globalnetEnabled)
+		Expect(err).To(Succeed())
+
+		ports := []string{}
+		for _, spec := range specs {
+			ports = append(ports, spec.Protocol+"/"+strconv.Itoa(spec.Port))
+		}
+
+		return ports
+	}
+
+	DescribeTable("per cable driver",
+		func(cableDriver string, expected ...string) {
+			Expect(portsOf(&deploy.SubmarinerOptions{CableDriver: cableDriver}, false)).
+				To(ConsistOf(append(expected, "UDP/4800", "TCP/8080")))
+		},
+		Entry("the default cable driver", "", "UDP/500", "UDP/4500", "ESP/0"),
+		Entry("libreswan", "libreswan", "UDP/500", "UDP/4500", "ESP/0"),
+		Entry("wireguard", "wireguard", "UDP/4500"),
+		Entry("vxlan", "vxlan", "UDP/4500"),
+	)
+
+	When("a custom NAT traversal port is used", func() {
+		It("should require it between gateways", func() {
+			Expect(portsOf(&deploy.SubmarinerOptions{NATTPort: 4501}, false)).To(ConsistOf("UDP/500", "UDP/4501", "ESP/0", "UDP/4800",
+				"TCP/8080"))
+		})
+	})
+
+	When("UDP encapsulation is forced and health checks are enabled", func() {
+		It("should not require ESP but require ICMP", func() {
+			Expect(portsOf(&deploy.SubmarinerOptions{ForceUDPEncaps: true, HealthCheckEnabled: true}, false)).
+				To(ConsistOf("UDP/500", "UDP/4500", "UDP/4800", "TCP/8080", "ICMP/0"))
+		})
+	})
+
+	When("globalnet is enabled", func() {
+		It("should require the globalnet metrics port", func() {
+			Expect(portsOf(&deploy.SubmarinerOptions{CableDriver: "wireguard"}, true)).
+				To(ConsistOf("UDP/4500", "UDP/4800", "TCP/8080", "TCP/8081"))
+		})
+	})
+
+	When("the cable driver is unknown", func() {
+		It("should return an error", func() {
+			_, err := (&deploy.SubmarinerOptions{CableDriver: "unknown"}).RequiredFirewallPorts(false)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
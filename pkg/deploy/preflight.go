@@ -0,0 +1,497 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/pods"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	"github.com/submariner-io/subctl/pkg/image"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/network"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	missingModulePrefix = "missing:"
+	kernelModulesDir    = "/lib/modules"
+	defaultCableDriver  = "libreswan"
+	dockerHubRegistry   = "registry-1.docker.io"
+	registryPingTimeout = 10 * time.Second
+	kubeProxyConfigMap  = "kube-proxy"
+	kubeProxyConfigKey  = "config.conf"
+)
+
+var cableDriverKernelModules = map[string][]string{
+	"libreswan": {"xfrm_user", "esp4"},
+	"wireguard": {"wireguard"},
+	"vxlan":     {"vxlan"},
+}
+
+// VerifyKernelModules runs a privileged pod, in the given namespace, on a gateway node to check that the kernel modules
+// required by the given cable driver, or the default one if it's empty, are loaded, built in, or loadable. Modules which
+// aren't in /sys/module are looked up in the running kernel's modules.builtin and modules.dep, since modules such as esp4
+// or wireguard are typically only loaded when the first tunnel is established.
+func VerifyKernelModules(ctx context.Context, kubeClient kubernetes.Interface, namespace, cableDriver string,
+	repositoryInfo *image.RepositoryInfo,
+) error {
+	if cableDriver == "" {
+		cableDriver = defaultCableDriver
+	}
+
+	modules, ok := cableDriverKernelModules[cableDriver]
+	if !ok {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "kernel module check cancelled")
+	}
+
+	podOutput, err := pods.ScheduleAndAwaitCompletion(&pods.Config{
+		Name:                "query-kernel-modules",
+		ClientSet:           kubeClient,
+		Scheduling:          pods.Scheduling{ScheduleOn: pods.GatewayNode, Networking: pods.HostNetworking},
+		Namespace:           namespace,
+		Command:             kernelModulesCommand(modules),
+		ImageRepositoryInfo: *repositoryInfo,
+		HostPaths:           []string{kernelModulesDir},
+	})
+	if err != nil {
+		return err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	missing := missingKernelModules(podOutput)
+	if len(missing) > 0 {
+		return fmt.Errorf("the %q cable driver requires the following kernel modules which are not available on the gateway node: %s",
+			cableDriver, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// kernelModulesCommand returns a shell command printing the given modules which are neither loaded, built in nor
+// available as loadable modules for the running kernel. Module file names may use dashes instead of underscores.
+func kernelModulesCommand(modules []string) string {
+	return "k=" + kernelModulesDir + "/$(uname -r); for m in " + strings.Join(modules, " ") + "; do " +
+		"[ -d /sys/module/$m ] && continue; " +
+		"p=$(echo $m | sed 's/[-_]/[-_]/g'); " +
+		"cat $k/modules.builtin $k/modules.dep 2>/dev/null | grep -qE \"(^|/)$p\\.ko\" && continue; " +
+		"echo " + missingModulePrefix + "$m; done"
+}
+
+func missingKernelModules(podOutput string) []string {
+	missing := []string{}
+
+	for _, line := range strings.Split(podOutput, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, missingModulePrefix) {
+			missing = append(missing, strings.TrimPrefix(line, missingModulePrefix))
+		}
+	}
+
+	return missing
+}
+
+// DetectServiceCIDR discovers the service CIDRs actually used by the cluster, from the network plugin configuration or
+// the API server; namespace is the Submariner namespace. An empty result means the service network couldn't be determined.
+func DetectServiceCIDR(ctx context.Context, client controllerClient.Client, namespace string) ([]string, error) {
+	networkDetails, err := network.Discover(ctx, client, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "error discovering the cluster network")
+	}
+
+	if networkDetails == nil {
+		return nil, nil
+	}
+
+	return networkDetails.ServiceCIDRs, nil
+}
+
+func checkServiceCIDR(ctx context.Context, client controllerClient.Client, namespace, serviceCIDR string,
+	status reporter.Interface,
+) {
+	detected, err := DetectServiceCIDR(ctx, client, namespace)
+	if err != nil {
+		status.Warning("Unable to verify the service CIDR: %s", err)
+		return
+	}
+
+	if len(detected) > 0 && !slices.Contains(detected, serviceCIDR) {
+		status.Warning("The service CIDR %s doesn't match the service network detected in the cluster (%s); "+
+			"service discovery may export incorrect IPs", serviceCIDR, strings.Join(detected, ", "))
+	}
+}
+
+// warnIfBrokerInsecure reports a warning when the broker's certificate won't be verified, which is easily enabled to get
+// past a self-signed certificate and then forgotten.
+func warnIfBrokerInsecure(insecure bool, status reporter.Interface) {
+	if insecure {
+		status.Warning("TLS VERIFICATION OF THE BROKER IS DISABLED: connections to the broker can be intercepted and the " +
+			"broker credentials stolen. If the broker uses a self-signed certificate, pin its CA bundle instead")
+	}
+}
+
+func checkKubeProxyMode(ctx context.Context, kubeClient kubernetes.Interface, status reporter.Interface) {
+	_, warnings, err := CheckKubeProxyMode(ctx, kubeClient)
+	if err != nil {
+		status.Warning("Unable to verify the kube-proxy mode: %s", err)
+		return
+	}
+
+	for _, warning := range warnings {
+		status.Warning("%s", warning)
+	}
+}
+
+// checkGatewayCount warns when fewer nodes are labeled as gateways than requested. The operator runs a gateway on every
+// labeled node, so the count can only be checked, not enforced.
+func checkGatewayCount(ctx context.Context, kubeClient kubernetes.Interface, requested int, status reporter.Interface) {
+	count, _, err := CountGatewayNodes(ctx, kubeClient)
+	if err != nil {
+		status.Warning("Unable to count the gateway nodes: %s", err)
+		return
+	}
+
+	if count < requested {
+		status.Warning("Only %d node(s) are labeled as gateways, fewer than the %d requested; label more nodes with %s=%s",
+			count, requested, constants.SubmarinerGatewayLabel, constants.TrueLabel)
+	}
+}
+
+// CableDriversCompatible determines whether clusters using the given cable drivers can connect to each other; if not,
+// the reason is returned. An empty driver is the default driver.
+func CableDriversCompatible(local, remote string) (bool, string) {
+	if local == "" {
+		local = defaultCableDriver
+	}
+
+	if remote == "" {
+		remote = defaultCableDriver
+	}
+
+	if local != remote {
+		return false, fmt.Sprintf("the %q cable driver can't connect to the %q cable driver, all clusters must use the same driver",
+			local, remote)
+	}
+
+	return true, ""
+}
+
+// CheckBrokerCableDrivers warns about any cluster registered with the broker using a cable driver incompatible with the
+// given one.
+func CheckBrokerCableDrivers(ctx context.Context, brokerClient controllerClient.Client, brokerNamespace, clusterID,
+	cableDriver string, status reporter.Interface,
+) error {
+	endpoints := &submarinerv1.EndpointList{}
+
+	err := brokerClient.List(ctx, endpoints, controllerClient.InNamespace(brokerNamespace))
+	if err != nil {
+		return errors.Wrap(err, "error listing the Endpoints registered with the broker")
+	}
+
+	for i := range endpoints.Items {
+		endpoint := &endpoints.Items[i].Spec
+		if endpoint.ClusterID == clusterID {
+			continue
+		}
+
+		if compatible, reason := CableDriversCompatible(cableDriver, endpoint.Backend); !compatible {
+			status.Warning("Cluster %q will not be able to connect to cluster %q: %s", clusterID, endpoint.ClusterID, reason)
+		}
+	}
+
+	return nil
+}
+
+// CheckPreferredServers verifies that enabling the preferred server setting on the given cluster doesn't bring the number of
+// preferred server clusters registered with the broker above the given maximum. Exceeding it is reported as a warning,
+// or as an error in strict mode.
+func CheckPreferredServers(ctx context.Context, brokerClient controllerClient.Client, brokerNamespace, clusterID string,
+	maxPreferredServers int, strict bool, status reporter.Interface,
+) error {
+	endpoints := &submarinerv1.EndpointList{}
+
+	err := brokerClient.List(ctx, endpoints, controllerClient.InNamespace(brokerNamespace))
+	if err != nil {
+		return errors.Wrap(err, "error listing the Endpoints registered with the broker")
+	}
+
+	preferredServers := []string{}
+
+	for i := range endpoints.Items {
+		endpoint := &endpoints.Items[i].Spec
+		if endpoint.ClusterID == clusterID || slices.Contains(preferredServers, endpoint.ClusterID) {
+			continue
+		}
+
+		if endpoint.BackendConfig[submarinerv1.PreferredServerConfig] == "true" {
+			preferredServers = append(preferredServers, endpoint.ClusterID)
+		}
+	}
+
+	if len(preferredServers) < maxPreferredServers {
+		return nil
+	}
+
+	err = fmt.Errorf("enabling the preferred server setting would exceed the maximum of %d preferred server clusters, "+
+		"it is already enabled on %s", maxPreferredServers, strings.Join(preferredServers, ", "))
+	if strict {
+		return err
+	}
+
+	status.Warning("%s", err)
+
+	return nil
+}
+
+// VerifyRepositoryReachable checks that the registry hosting the given image repository answers the registry v2 API.
+// Both authorized and unauthorized responses are considered successful, since they show the registry is there.
+func VerifyRepositoryReachable(repository string) error {
+	url := "https://" + registryHost(repository) + "/v2/"
+
+	request, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return errors.Wrapf(err, "error creating a request for %q", url)
+	}
+
+	response, err := (&http.Client{Timeout: registryPingTimeout}).Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "the image registry for repository %q can't be reached, check the repository name and "+
+			"the network connectivity, or disable this check if the registry is only reachable from the cluster", repository)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("%q doesn't appear to be an image registry (%s returned %q)", registryHost(repository), url,
+			response.Status)
+	}
+
+	return nil
+}
+
+// registryHost extracts the registry host from a repository, following the same rules as container runtimes: the first
+// path component is a host if it contains a dot or a port, or is "localhost"; otherwise the repository is on Docker Hub.
+func registryHost(repository string) string {
+	host, _, found := strings.Cut(repository, "/")
+	if found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		return host
+	}
+
+	return dockerHubRegistry
+}
+
+// CheckBrokerVersion verifies that the Submariner version deployed on the broker is compatible with the given version.
+// Clusters are expected to be within one minor version of the broker: a larger skew is an error, a one minor version skew
+// is reported as a warning. Versions which aren't semantic versions (e.g. "devel") aren't checked, and neither are
+// brokers which don't record their version.
+func CheckBrokerVersion(ctx context.Context, brokerClient controllerClient.Client, brokerNamespace, version string,
+	status reporter.Interface,
+) error {
+	brokerCR := &operatorv1alpha1.Broker{}
+
+	err := brokerClient.Get(ctx, controllerClient.ObjectKey{Namespace: brokerNamespace, Name: brokercr.Name}, brokerCR)
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "error retrieving the Broker resource")
+	}
+
+	brokerVersion := brokerCR.Annotations[constants.SubmarinerVersionAnnotation]
+
+	compatible, skewed := VersionsCompatible(brokerVersion, version)
+	if !compatible {
+		return fmt.Errorf("version %s isn't compatible with the broker's version %s, clusters must be within one minor "+
+			"version of the broker", version, brokerVersion)
+	}
+
+	if skewed {
+		status.Warning("Version %s differs from the broker's version %s, consider upgrading the older one", version, brokerVersion)
+	}
+
+	return nil
+}
+
+// VersionsCompatible compares two Submariner versions, returning whether they are compatible, and if so, whether they are
+// skewed (one minor version apart). Versions which can't be compared are considered compatible.
+func VersionsCompatible(brokerVersion, clusterVersion string) (compatible, skewed bool) {
+	broker, err := semver.NewVersion(strings.TrimPrefix(brokerVersion, "v"))
+	if err != nil {
+		return true, false
+	}
+
+	cluster, err := semver.NewVersion(strings.TrimPrefix(clusterVersion, "v"))
+	if err != nil {
+		return true, false
+	}
+
+	if broker.Major != cluster.Major {
+		return false, false
+	}
+
+	minorSkew := broker.Minor - cluster.Minor
+	if cluster.Minor > broker.Minor {
+		minorSkew = cluster.Minor - broker.Minor
+	}
+
+	return minorSkew <= 1, minorSkew == 1
+}
+
+// CountGatewayNodes returns the number of nodes labeled as gateways, and their names.
+func CountGatewayNodes(ctx context.Context, kubeClient kubernetes.Interface) (int, []string, error) {
+	selector := labels.SelectorFromSet(map[string]string{constants.SubmarinerGatewayLabel: constants.TrueLabel})
+
+	gwNodes, err := kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	nodeNames := make([]string, len(gwNodes.Items))
+	for i := range gwNodes.Items {
+		nodeNames[i] = gwNodes.Items[i].Name
+	}
+
+	return len(nodeNames), nodeNames, nil
+}
+
+// CheckKubeProxyMode determines the kube-proxy mode from the kube-proxy configuration in kube-system, and returns it along
+// with any warnings relevant to Submariner. An empty mode means the kube-proxy configuration couldn't be found, e.g.
+// because the cluster uses a different service proxy.
+func CheckKubeProxyMode(ctx context.Context, kubeClient kubernetes.Interface) (string, []string, error) {
+	configMap, err := kubeClient.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, kubeProxyConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", nil, nil
+	}
+
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error retrieving the kube-proxy configuration")
+	}
+
+	config := struct {
+		Mode string `json:"mode"`
+		IPVS struct {
+			StrictARP bool `json:"strictARP"`
+		} `json:"ipvs"`
+	}{}
+
+	err = yaml.Unmarshal([]byte(configMap.Data[kubeProxyConfigKey]), &config)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error parsing the kube-proxy configuration")
+	}
+
+	warnings := []string{}
+
+	switch config.Mode {
+	case "", "iptables":
+		return "iptables", warnings, nil
+	case "ipvs":
+		warnings = append(warnings, "kube-proxy runs in ipvs mode, which Submariner hasn't been validated with")
+
+		if config.IPVS.StrictARP {
+			warnings = append(warnings, "kube-proxy has strict ARP enabled, which prevents the gateway from answering ARP "+
+				"requests for exported service IPs")
+		}
+	default:
+		warnings = append(warnings, fmt.Sprintf("kube-proxy runs in %s mode, which Submariner hasn't been validated with",
+			config.Mode))
+	}
+
+	return config.Mode, warnings, nil
+}
+
+// checkReferencedObjects verifies that the secrets and config maps referenced by the given spec exist, and returns a single
+// error listing all the missing ones. The PSK secret is skipped when it is synchronised from an external secret store,
+// since it may not have been created yet.
+func checkReferencedObjects(ctx context.Context, kubeClient kubernetes.Interface, spec *operatorv1alpha1.SubmarinerSpec,
+	pskFromExternalStore bool,
+) error {
+	type reference struct {
+		get         func() error
+		description string
+	}
+
+	references := []reference{}
+
+	addSecret := func(name, purpose string) {
+		if name == "" {
+			return
+		}
+
+		references = append(references, reference{
+			get: func() error {
+				_, err := kubeClient.CoreV1().Secrets(spec.Namespace).Get(ctx, name, metav1.GetOptions{})
+				return err //nolint:wrapcheck // No need to wrap errors here.
+			},
+			description: fmt.Sprintf("%s secret %s/%s", purpose, spec.Namespace, name),
+		})
+	}
+
+	addSecret(spec.BrokerK8sSecret, "broker")
+
+	if !pskFromExternalStore {
+		addSecret(spec.CeIPSecPSKSecret, "IPsec PSK")
+	}
+
+	if spec.CoreDNSCustomConfig != nil {
+		ref := spec.CoreDNSCustomConfig
+		references = append(references, reference{
+			get: func() error {
+				_, err := kubeClient.CoreV1().ConfigMaps(ref.Namespace).Get(ctx, ref.ConfigMapName, metav1.GetOptions{})
+				return err //nolint:wrapcheck // No need to wrap errors here.
+			},
+			description: fmt.Sprintf("CoreDNS custom config map %s/%s", ref.Namespace, ref.ConfigMapName),
+		})
+	}
+
+	missing := []string{}
+
+	for _, ref := range references {
+		err := ref.get()
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, ref.description)
+		} else if err != nil {
+			return errors.Wrapf(err, "error retrieving the %s", ref.description)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the Submariner resource references missing objects: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,391 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	"github.com/submariner-io/subctl/pkg/image"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CableDriversCompatible", func() {
+	When("both clusters use the same cable driver", func() {
+		It("should report them as compatible", func() {
+			compatible, reason := deploy.CableDriversCompatible("wireguard", "wireguard")
+			Expect(compatible).To(BeTrue())
+			Expect(reason).To(BeEmpty())
+		})
+	})
+
+	When("one cluster uses the default cable driver", func() {
+		It("should treat it as libreswan", func() {
+			compatible, _ := deploy.CableDriversCompatible("", "libreswan")
+			Expect(compatible).To(BeTrue())
+
+			compatible, _ = deploy.CableDriversCompatible("wireguard", "")
+			Expect(compatible).To(BeFalse())
+		})
+	})
+
+	When("the clusters use different cable drivers", func() {
+		It("should report them as incompatible with a reason", func() {
+			compatible, reason := deploy.CableDriversCompatible("wireguard", "libreswan")
+			Expect(compatible).To(BeFalse())
+			Expect(reason).To(ContainSubstring("wireguard"))
+			Expect(reason).To(ContainSubstring("libreswan"))
+		})
+	})
+})
+
+var _ = Describe("CountGatewayNodes", func() {
+	newNode := func(name string, nodeLabels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels}}
+	}
+
+	It("should return the nodes labeled as gateways", func() {
+		kubeClient := fake.NewSimpleClientset(
+			newNode("node1", map[string]string{constants.SubmarinerGatewayLabel: constants.TrueLabel}),
+			newNode("node2", map[string]string{constants.SubmarinerGatewayLabel: "false"}),
+			newNode("node3", nil),
+			newNode("node4", map[string]string{constants.SubmarinerGatewayLabel: constants.TrueLabel}))
+
+		count, nodeNames, err := deploy.CountGatewayNodes(context.TODO(), kubeClient)
+		Expect(err).To(Succeed())
+		Expect(count).To(Equal(2))
+		Expect(nodeNames).To(ConsistOf("node1", "node4"))
+	})
+})
+
+var _ = Describe("CheckPreferredServers", func() {
+	const brokerNamespace = "submariner-k8s-broker"
+
+	var brokerClient controllerClient.Client
+
+	newEndpoint := func(clusterID string, preferredServer bool) *submarinerv1.Endpoint {
+		endpoint := &submarinerv1.Endpoint{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterID + "-endpoint", Namespace: brokerNamespace},
+			Spec: submarinerv1.EndpointSpec{
+				ClusterID:     clusterID,
+				BackendConfig: map[string]string{},
+			},
+		}
+
+		if preferredServer {
+			endpoint.Spec.BackendConfig[submarinerv1.PreferredServerConfig] = "true"
+		}
+
+		return endpoint
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(submarinerv1.AddToScheme(scheme)).To(Succeed())
+
+		brokerClient = fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(newEndpoint("east", false),
+			newEndpoint("west", true), newEndpoint("north", true)).Build()
+	})
+
+	When("the maximum isn't reached", func() {
+		It("should succeed", func() {
+			Expect(deploy.CheckPreferredServers(context.TODO(), brokerClient, brokerNamespace, "east", 3, true,
+				reporter.Silent())).To(Succeed())
+		})
+	})
+
+	When("the maximum would be exceeded", func() {
+		It("should only warn in non-strict mode", func() {
+			Expect(deploy.CheckPreferredServers(context.TODO(), brokerClient, brokerNamespace, "east", 2, false,
+				reporter.Silent())).To(Succeed())
+		})
+
+		It("should return an error in strict mode", func() {
+			Expect(deploy.CheckPreferredServers(context.TODO(), brokerClient, brokerNamespace, "east", 2, true,
+				reporter.Silent())).To(MatchError(ContainSubstring("west")))
+		})
+	})
+
+	When("the cluster itself is already a preferred server", func() {
+		It("should not count it", func() {
+			Expect(deploy.CheckPreferredServers(context.TODO(), brokerClient, brokerNamespace, "west", 2, true,
+				reporter.Silent())).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("CheckKubeProxyMode", func() {
+	newConfigMap := func(config string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-proxy", Namespace: metav1.NamespaceSystem},
+			Data:       map[string]string{"config.conf": config},
+		}
+	}
+
+	When("the kube-proxy configuration doesn't exist", func() {
+		It("should return an empty mode", func() {
+			mode, warnings, err := deploy.CheckKubeProxyMode(context.TODO(), fake.NewSimpleClientset())
+			Expect(err).To(Succeed())
+			Expect(mode).To(BeEmpty())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	When("the mode isn't set", func() {
+		It("should default to iptables without warnings", func() {
+			mode, warnings, err := deploy.CheckKubeProxyMode(context.TODO(), fake.NewSimpleClientset(newConfigMap("mode: \"\"\n")))
+			Expect(err).To(Succeed())
+			Expect(mode).To(Equal("iptables"))
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	When("kube-proxy runs in ipvs mode with strict ARP", func() {
+		It("should return both warnings", func() {
+			mode, warnings, err := deploy.CheckKubeProxyMode(context.TODO(),
+				fake.NewSimpleClientset(newConfigMap("mode: ipvs\nipvs:\n  strictARP: true\n")))
+			Expect(err).To(Succeed())
+			Expect(mode).To(Equal("ipvs"))
+			Expect(warnings).To(HaveLen(2))
+		})
+	})
+})
+
+var _ = Describe("Verifying the referenced objects", func() {
+	var (
+		kubeClient     *fake.Clientset
+		clientProducer *client.DefaultProducer
+		options        *deploy.SubmarinerOptions
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		kubeClient = fake.NewSimpleClientset()
+		clientProducer = &client.DefaultProducer{
+			KubeClient:    kubeClient,
+			GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).Build(),
+		}
+
+		options = newTestSubmarinerOptions()
+		options.VerifyReferencedObjects = true
+		options.CoreDNSCustomConfigMap = "dns/coredns-custom"
+	})
+
+	deploySubmariner := func() (*operatorv1alpha1.Submariner, error) {
+		brokerInfo, brokerSecret := newTestBrokerInfo()
+
+		return deploy.Submariner(context.TODO(), clientProducer, options, brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", "", nil), reporter.Silent())
+	}
+
+	When("referenced objects are missing", func() {
+		It("should return an error listing all of them", func() {
+			_, err := deploySubmariner()
+			Expect(err).To(MatchError(ContainSubstring("broker secret submariner-operator/broker-secret-abcde")))
+			Expect(err).To(MatchError(ContainSubstring("CoreDNS custom config map dns/coredns-custom")))
+			Expect(err).ToNot(MatchError(ContainSubstring("IPsec PSK")))
+		})
+	})
+
+	When("all the referenced objects exist", func() {
+		It("should deploy and record the subctl version", func() {
+			_, err := kubeClient.CoreV1().Secrets(constants.OperatorNamespace).Create(context.TODO(), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "broker-secret-abcde"},
+			}, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+
+			_, err = kubeClient.CoreV1().ConfigMaps("dns").Create(context.TODO(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "coredns-custom"},
+			}, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+
+			submariner, err := deploySubmariner()
+			Expect(err).To(Succeed())
+			Expect(submariner.Spec.ClusterID).To(Equal(options.ClusterID))
+			Expect(submariner.Namespace).To(Equal(constants.OperatorNamespace))
+			Expect(submariner.Annotations).To(HaveKey(constants.SubctlDeployedByVersionAnnotation))
+
+			existing := &operatorv1alpha1.Submariner{}
+			Expect(clientProducer.GeneralClient.Get(context.TODO(), controllerClient.ObjectKeyFromObject(submariner),
+				existing)).To(Succeed())
+			Expect(existing.Annotations).To(HaveKey(constants.SubctlDeployedByVersionAnnotation))
+		})
+
+		It("should keep the existing resources when redeploying", func() {
+			_, err := kubeClient.CoreV1().Secrets(constants.OperatorNamespace).Create(context.TODO(), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "broker-secret-abcde"},
+			}, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+
+			options.CoreDNSCustomConfigMap = ""
+
+			submariner, err := deploySubmariner()
+			Expect(err).To(Succeed())
+
+			// The status is ignored when comparing, it only survives if the resource isn't recreated
+			submariner.Status.ClusterID = "marker"
+			Expect(clientProducer.GeneralClient.Update(context.TODO(), submariner)).To(Succeed())
+
+			kubeClient.ClearActions()
+
+			_, err = deploySubmariner()
+			Expect(err).To(Succeed())
+
+			existing := &operatorv1alpha1.Submariner{}
+			Expect(clientProducer.GeneralClient.Get(context.TODO(), controllerClient.ObjectKeyFromObject(submariner),
+				existing)).To(Succeed())
+			Expect(existing.Status.ClusterID).To(Equal("marker"))
+
+			for _, action := range kubeClient.Actions() {
+				Expect(action.GetVerb()).ToNot(Equal("delete"), "unexpected action %#v", action)
+			}
+		})
+	})
+
+	Context("the kube-proxy mode check", func() {
+		kubeProxyChecked := func() bool {
+			for _, action := range kubeClient.Actions() {
+				if action.GetNamespace() == metav1.NamespaceSystem && action.GetResource().Resource == "configmaps" {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		BeforeEach(func() {
+			options.VerifyReferencedObjects = false
+		})
+
+		It("should only run when requested", func() {
+			_, err := deploySubmariner()
+			Expect(err).To(Succeed())
+			Expect(kubeProxyChecked()).To(BeFalse())
+
+			options.VerifyKubeProxyMode = true
+			_, err = deploySubmariner()
+			Expect(err).To(Succeed())
+			Expect(kubeProxyChecked()).To(BeTrue())
+		})
+
+		It("should not run in dry-run mode", func() {
+			options.VerifyKubeProxyMode = true
+			options.DryRun = true
+			options.DryRunOutput = io.Discard
+
+			_, err := deploySubmariner()
+			Expect(err).To(Succeed())
+			Expect(kubeProxyChecked()).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("VerifyKernelModules", func() {
+	var (
+		kubeClient *fake.Clientset
+		missing    string
+		commands   []string
+	)
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: constants.OperatorNamespace}})
+		missing = ""
+		commands = nil
+
+		// The pods complete as soon as they're retrieved, reporting the configured missing modules
+		kubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+			pod.Name = pod.GenerateName + "test"
+
+			for _, env := range pod.Spec.Containers[0].Env {
+				if env.Name == "COMMAND" {
+					commands = append(commands, env.Value)
+				}
+			}
+
+			return false, nil, nil
+		})
+
+		kubeClient.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: action.(k8stesting.GetAction).GetName(), Namespace: action.GetNamespace()},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodSucceeded,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: missing}},
+					}},
+				},
+			}, nil
+		})
+	})
+
+	verify := func(cableDriver string) error {
+		return deploy.VerifyKernelModules(context.TODO(), kubeClient, constants.OperatorNamespace, cableDriver,
+			image.NewRepositoryInfo("", "", nil))
+	}
+
+	DescribeTable("checking the modules required by the cable driver",
+		func(cableDriver string, expectedModules []string) {
+			Expect(verify(cableDriver)).To(Succeed())
+			Expect(commands).To(HaveLen(1))
+
+			for _, module := range expectedModules {
+				Expect(commands[0]).To(ContainSubstring(" " + module + " "))
+			}
+		},
+		Entry("the default cable driver", "", []string{"xfrm_user", "esp4;"}),
+		Entry("libreswan", "libreswan", []string{"xfrm_user", "esp4;"}),
+		Entry("wireguard", "wireguard", []string{"wireguard;"}),
+		Entry("vxlan", "vxlan", []string{"vxlan;"}),
+	)
+
+	When("the cable driver doesn't require any known module", func() {
+		It("should not run the check", func() {
+			Expect(verify("custom")).To(Succeed())
+			Expect(commands).To(BeEmpty())
+		})
+	})
+
+	When("a module is missing", func() {
+		It("should return an error naming the cable driver and the module", func() {
+			missing = "missing:esp4\n"
+
+			err := verify("")
+			Expect(err).To(MatchError(ContainSubstring(`"libreswan" cable driver`)))
+			Expect(err).To(MatchError(ContainSubstring("esp4")))
+		})
+	})
+})
@@ -0,0 +1,198 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	"github.com/submariner-io/subctl/pkg/client"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	readyCheckInterval      = 5 * time.Second
+	defaultReadyWaitTimeout = 5 * time.Minute
+)
+
+// NotReadyError is returned by WaitForSubmarinerReady when the Submariner components didn't become ready in time.
+type NotReadyError struct {
+	// Components lists the components which weren't ready, with the reason when known.
+	Components []string
+	Timeout    time.Duration
+}
+
+func (e *NotReadyError) Error() string {
+	return fmt.Sprintf("timed out after %v waiting for %s to be ready", e.Timeout, strings.Join(e.Components, ", "))
+}
+
+// WaitForSubmarinerReady waits for the Submariner resource in the given namespace to report healthy gateway and
+// route agent DaemonSets, and for the DaemonSets and Deployments of the components it enables to be ready. Progress
+// is reported to the given status as components become ready. On timeout, a *NotReadyError listing the components
+// which aren't ready is returned.
+func WaitForSubmarinerReady(ctx context.Context, clientProducer client.Producer, namespace string, timeout time.Duration,
+	status reporter.Interface,
+) error {
+	if timeout <= 0 {
+		timeout = defaultReadyWaitTimeout
+	}
+
+	status.Start("Waiting for the Submariner components to be ready")
+	defer status.End()
+
+	reported := stringset.New()
+
+	var notReady []string
+
+	err := wait.PollImmediate(readyCheckInterval, timeout, func() (bool, error) {
+		submariner := &operatorv1alpha1.Submariner{}
+
+		err := clientProducer.ForGeneral().Get(ctx, controllerClient.ObjectKey{Namespace: namespace, Name: names.SubmarinerCrName},
+			submariner)
+		if err != nil {
+			return false, errors.Wrap(err, "error retrieving the Submariner resource")
+		}
+
+		notReady, err = notReadyComponents(ctx, clientProducer, submariner)
+		if err != nil {
+			return false, err
+		}
+
+		pending := stringset.New()
+		for _, entry := range notReady {
+			pending.Add(componentName(entry))
+		}
+
+		for _, name := range readyCandidates(submariner) {
+			if !pending.Contains(name) && reported.Add(name) {
+				status.Success("%s is ready", name)
+			}
+		}
+
+		return len(notReady) == 0, nil
+	})
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return status.Error(&NotReadyError{Components: notReady, Timeout: timeout}, "Submariner isn't ready")
+	}
+
+	return status.Error(err, "Error waiting for Submariner to be ready")
+}
+
+// readyCandidates returns the names of the DaemonSets and Deployments expected for the given Submariner resource.
+func readyCandidates(submariner *operatorv1alpha1.Submariner) []string {
+	daemonSets, deployments := expectedComponents(submariner)
+
+	return append(daemonSets, deployments...)
+}
+
+// componentName strips the reason, if any, from an entry returned by notReadyComponents.
+func componentName(entry string) string {
+	return strings.SplitN(entry, " ", 2)[0]
+}
+
+func expectedComponents(submariner *operatorv1alpha1.Submariner) (daemonSets, deployments []string) {
+	daemonSets = []string{names.GatewayComponent, names.RouteAgentComponent}
+	if submariner.Spec.GlobalCIDR != "" {
+		daemonSets = append(daemonSets, names.GlobalnetComponent)
+	}
+
+	deployments = []string{}
+	if submariner.Spec.ServiceDiscoveryEnabled {
+		deployments = append(deployments, names.ServiceDiscoveryComponent, names.LighthouseCoreDNSComponent)
+	}
+
+	return daemonSets, deployments
+}
+
+func notReadyComponents(ctx context.Context, clientProducer client.Producer, submariner *operatorv1alpha1.Submariner,
+) ([]string, error) {
+	daemonSets, deployments := expectedComponents(submariner)
+
+	// The operator mirrors the gateway and route agent DaemonSet states in the Submariner status; when it reports
+	// problems there, use them since they're more specific than the DaemonSet counters.
+	statusProblems := map[string]string{
+		names.GatewayComponent:    daemonSetStatusProblem(&submariner.Status.GatewayDaemonSetStatus),
+		names.RouteAgentComponent: daemonSetStatusProblem(&submariner.Status.RouteAgentDaemonSetStatus),
+	}
+
+	notReady := []string{}
+	apps := clientProducer.ForKubernetes().AppsV1()
+
+	for _, name := range daemonSets {
+		if problem := statusProblems[name]; problem != "" {
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", name, problem))
+			continue
+		}
+
+		daemonSet, err := apps.DaemonSets(submariner.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "error retrieving the %q DaemonSet", name)
+		}
+
+		if err != nil || !isDaemonSetReady(daemonSet) {
+			notReady = append(notReady, name)
+		}
+	}
+
+	for _, name := range deployments {
+		deployment, err := apps.Deployments(submariner.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "error retrieving the %q Deployment", name)
+		}
+
+		if err != nil || !isAvailable(deployment) {
+			notReady = append(notReady, name)
+		}
+	}
+
+	return notReady, nil
+}
+
+// daemonSetStatusProblem returns a description of the problem reported by the operator for a DaemonSet, if any.
+func daemonSetStatusProblem(status *operatorv1alpha1.DaemonSetStatusWrapper) string {
+	if status.MismatchedContainerImages {
+		return "mismatched container images"
+	}
+
+	if status.NonReadyContainerStates != nil && len(*status.NonReadyContainerStates) > 0 {
+		return fmt.Sprintf("%d container(s) not ready", len(*status.NonReadyContainerStates))
+	}
+
+	return ""
+}
+
+// isDaemonSetReady returns true if the given DaemonSet has been fully rolled out, with at least one ready pod.
+func isDaemonSetReady(daemonSet *appsv1.DaemonSet) bool {
+	status := &daemonSet.Status
+
+	return status.ObservedGeneration >= daemonSet.Generation && status.DesiredNumberScheduled > 0 &&
+		status.UpdatedNumberScheduled == status.DesiredNumberScheduled && status.NumberReady == status.DesiredNumberScheduled
+}
@@ -0,0 +1,143 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	"github.com/submariner-io/subctl/pkg/image"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Waiting for Submariner to be ready", func() {
+	var (
+		kubeClient     *fake.Clientset
+		clientProducer *client.DefaultProducer
+		options        *deploy.SubmarinerOptions
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		kubeClient = fake.NewSimpleClientset()
+		clientProducer = &client.DefaultProducer{
+			KubeClient:    kubeClient,
+			GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).Build(),
+		}
+
+		options = newTestSubmarinerOptions()
+		options.WaitForReady = true
+		options.ReadyWaitTimeout = 10 * time.Millisecond
+	})
+
+	deploySubmariner := func() error {
+		brokerInfo, brokerSecret := newTestBrokerInfo()
+
+		_, err := deploy.Submariner(context.TODO(), clientProducer, options, brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", "", nil), reporter.Silent())
+
+		return err
+	}
+
+	createDaemonSet := func(name string, ready int32) {
+		_, err := kubeClient.AppsV1().DaemonSets(constants.OperatorNamespace).Create(context.TODO(), &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 2,
+				UpdatedNumberScheduled: 2,
+				NumberReady:            ready,
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+	}
+
+	When("the components aren't ready", func() {
+		It("should time out listing them", func() {
+			createDaemonSet(names.RouteAgentComponent, 1)
+
+			err := deploySubmariner()
+			Expect(err).To(MatchError(ContainSubstring(names.GatewayComponent)))
+			Expect(err).To(MatchError(ContainSubstring(names.RouteAgentComponent)))
+		})
+	})
+
+	createDeployment := func(name string) {
+		_, err := kubeClient.AppsV1().Deployments(constants.OperatorNamespace).Create(context.TODO(), &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+	}
+
+	When("the components are ready", func() {
+		It("should succeed", func() {
+			createDaemonSet(names.GatewayComponent, 2)
+			createDaemonSet(names.RouteAgentComponent, 2)
+			createDeployment(names.ServiceDiscoveryComponent)
+			createDeployment(names.LighthouseCoreDNSComponent)
+
+			Expect(deploySubmariner()).To(Succeed())
+		})
+	})
+
+	When("the Submariner status reports mismatched gateway images", func() {
+		It("should return a NotReadyError naming the gateway", func() {
+			createDaemonSet(names.GatewayComponent, 2)
+			createDaemonSet(names.RouteAgentComponent, 2)
+
+			options.WaitForReady = false
+			Expect(deploySubmariner()).To(Succeed())
+
+			submariner := &operatorv1alpha1.Submariner{}
+			key := controllerClient.ObjectKey{Namespace: constants.OperatorNamespace, Name: names.SubmarinerCrName}
+			Expect(clientProducer.GeneralClient.Get(context.TODO(), key, submariner)).To(Succeed())
+
+			submariner.Status.GatewayDaemonSetStatus.MismatchedContainerImages = true
+			Expect(clientProducer.GeneralClient.Update(context.TODO(), submariner)).To(Succeed())
+
+			err := deploy.WaitForSubmarinerReady(context.TODO(), clientProducer, constants.OperatorNamespace, 10*time.Millisecond,
+				reporter.Silent())
+
+			notReadyErr := &deploy.NotReadyError{}
+			Expect(errors.As(err, &notReadyErr)).To(BeTrue())
+			Expect(notReadyErr.Components).To(ContainElement(names.GatewayComponent + " (mismatched container images)"))
+			Expect(notReadyErr.Components).ToNot(ContainElement(names.RouteAgentComponent))
+		})
+	})
+})
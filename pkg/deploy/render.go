@@ -0,0 +1,156 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/image"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	redactedToken = "##redacted-token##"
+	redactedPSK   = "##redacted-ipsec-psk##"
+)
+
+// RenderSubmariner renders the Submariner resource that Submariner would deploy with the given parameters, without
+// applying anything. The output is YAML with sorted keys and without status or server-populated metadata, and the broker
+// token and IPsec PSK are replaced with placeholders, so that it can be committed and diffed meaningfully.
+func RenderSubmariner(options *SubmarinerOptions, brokerInfo *broker.Info, brokerSecret *v1.Secret, netconfig globalnet.Config,
+	repositoryInfo *image.RepositoryInfo,
+) ([]byte, error) {
+	err := ValidateSecretNameSuffix(options.SecretNameSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	pskSecret, err := pskSecretFor(options, brokerInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	submarinerSpec, err := populateSubmarinerSpec(options, brokerInfo, brokerSecret, pskSecret, netconfig, repositoryInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderObject(newRenderedSubmariner(options.namespace(), submarinerSpec))
+}
+
+// newRenderedSubmariner returns the Submariner resource with the given spec, with its secrets replaced by placeholders.
+func newRenderedSubmariner(namespace string, submarinerSpec *operatorv1alpha1.SubmarinerSpec) *operatorv1alpha1.Submariner {
+	submarinerSpec = submarinerSpec.DeepCopy()
+
+	if submarinerSpec.CeIPSecPSK != "" {
+		submarinerSpec.CeIPSecPSK = redactedPSK
+	}
+
+	if submarinerSpec.BrokerK8sApiServerToken != "" {
+		submarinerSpec.BrokerK8sApiServerToken = redactedToken
+	}
+
+	return &operatorv1alpha1.Submariner{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: operatorv1alpha1.GroupVersion.String(),
+			Kind:       "Submariner",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      names.SubmarinerCrName,
+			Namespace: namespace,
+			Labels:    versionLabels(),
+		},
+		Spec: *submarinerSpec,
+	}
+}
+
+// renderDryRun writes the PSK secret (or its ExternalSecret) and the Submariner resource which would be deployed, as a
+// multi-document YAML stream, to the dry-run output. The PSK and broker token are replaced with placeholders. An existing
+// PSK secret isn't rendered since subctl doesn't manage it.
+func renderDryRun(options *SubmarinerOptions, pskSecret *v1.Secret, submarinerSpec *operatorv1alpha1.SubmarinerSpec) error {
+	var pskObject runtime.Object = &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pskSecret.Name,
+			Namespace: options.namespace(),
+		},
+		StringData: map[string]string{"psk": redactedPSK},
+	}
+
+	if options.PSKExternalSecretStore != "" {
+		externalSecret, err := newPSKExternalSecret(options, options.namespace(), pskSecret.Name)
+		if err != nil {
+			return err
+		}
+
+		pskObject = externalSecret
+	}
+
+	output := options.DryRunOutput
+	if output == nil {
+		output = os.Stdout
+	}
+
+	objects := []runtime.Object{pskObject, newRenderedSubmariner(options.namespace(), submarinerSpec)}
+	if options.ExistingPSKSecret != "" {
+		objects = objects[1:]
+	}
+
+	for _, obj := range objects {
+		rendered, err := renderObject(obj)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(output, "---\n%s", rendered)
+		if err != nil {
+			return errors.Wrap(err, "error writing the rendered resources")
+		}
+	}
+
+	return nil
+}
+
+func renderObject(obj runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting the resource")
+	}
+
+	unstructured.RemoveNestedField(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+	// Maps are marshalled with sorted keys, which keeps the output stable
+	output, err := yaml.Marshal(content)
+
+	return output, errors.Wrap(err, "error marshalling the resource to YAML")
+}
@@ -0,0 +1,190 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	"github.com/submariner-io/subctl/pkg/image"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testToken = "secret-broker-token"
+	testPSK   = "secret-psk"
+)
+
+func newTestBrokerInfo() (*broker.Info, *v1.Secret) {
+	brokerSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker-secret-abcde"},
+		Data: map[string][]byte{
+			"ca.crt":    []byte("ca"),
+			"namespace": []byte("submariner-k8s-broker"),
+			"token":     []byte(testToken),
+		},
+	}
+
+	return &broker.Info{
+		BrokerURL:   "https://broker.example.com:6443",
+		ClientToken: brokerSecret,
+		IPSecPSK: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "submariner-ipsec-psk"},
+			Data:       map[string][]byte{"psk": []byte(testPSK)},
+		},
+		Components: []string{"service-discovery", "connectivity"},
+	}, brokerSecret
+}
+
+func newTestSubmarinerOptions() *deploy.SubmarinerOptions {
+	return &deploy.SubmarinerOptions{
+		ClusterID:     "east",
+		CableDriver:   "libreswan",
+		ServiceCIDR:   "10.96.0.0/16",
+		ClusterCIDR:   "10.244.0.0/16",
+		NATTPort:      4500,
+		CustomDomains: []string{"b.example.com", "a.example.com"},
+	}
+}
+
+var _ = Describe("RenderSubmariner", func() {
+	var (
+		brokerInfo   *broker.Info
+		brokerSecret *v1.Secret
+		output       string
+	)
+
+	BeforeEach(func() {
+		brokerInfo, brokerSecret = newTestBrokerInfo()
+
+		rendered, err := deploy.RenderSubmariner(newTestSubmarinerOptions(), brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", "", nil))
+		Expect(err).To(Succeed())
+
+		output = string(rendered)
+	})
+
+	It("should render the Submariner resource", func() {
+		Expect(output).To(ContainSubstring("kind: Submariner"))
+		Expect(output).To(ContainSubstring("clusterID: east"))
+		Expect(output).To(ContainSubstring("brokerK8sApiServer: broker.example.com:6443"))
+		Expect(output).ToNot(ContainSubstring("status:"))
+		Expect(output).ToNot(ContainSubstring("creationTimestamp"))
+	})
+
+	It("should redact the secrets", func() {
+		Expect(output).ToNot(ContainSubstring(testToken))
+		Expect(output).To(ContainSubstring("##redacted-token##"))
+		Expect(output).To(ContainSubstring("##redacted-ipsec-psk##"))
+	})
+
+	It("should produce stable output", func() {
+		rendered, err := deploy.RenderSubmariner(newTestSubmarinerOptions(), brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", "", nil))
+		Expect(err).To(Succeed())
+		Expect(string(rendered)).To(Equal(output))
+	})
+})
+
+var _ = Describe("Omitting the inline broker fields", func() {
+	render := func(version string) string {
+		options := newTestSubmarinerOptions()
+		options.OmitInlineBrokerFields = true
+		brokerInfo, brokerSecret := newTestBrokerInfo()
+
+		rendered, err := deploy.RenderSubmariner(options, brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", version, nil))
+		Expect(err).To(Succeed())
+
+		return string(rendered)
+	}
+
+	When("the version no longer needs them", func() {
+		It("should omit them", func() {
+			output := render("0.15.0")
+			Expect(output).ToNot(ContainSubstring("brokerK8sApiServerToken"))
+			Expect(output).ToNot(ContainSubstring("ceIPSecPSK:"))
+			Expect(output).To(ContainSubstring("brokerK8sSecret: broker-secret-abcde"))
+			Expect(output).To(ContainSubstring("ceIPSecPSKSecret: submariner-ipsec-psk"))
+			Expect(output).To(ContainSubstring("brokerK8sRemoteNamespace: submariner-k8s-broker"))
+		})
+	})
+
+	When("the version still needs them", func() {
+		It("should keep them", func() {
+			output := render("0.12.1")
+			Expect(output).To(ContainSubstring("brokerK8sApiServerToken"))
+			Expect(output).To(ContainSubstring("ceIPSecPSK:"))
+		})
+	})
+})
+
+var _ = Describe("Rendering in a custom namespace", func() {
+	It("should use the namespace for the resource and its components", func() {
+		options := newTestSubmarinerOptions()
+		options.Namespace = "tenant-a"
+		brokerInfo, brokerSecret := newTestBrokerInfo()
+
+		rendered, err := deploy.RenderSubmariner(options, brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", "", nil))
+		Expect(err).To(Succeed())
+		Expect(string(rendered)).To(ContainSubstring("  namespace: tenant-a\n"))
+		Expect(string(rendered)).ToNot(ContainSubstring("submariner-operator"))
+	})
+})
+
+var _ = Describe("Deploying in dry-run mode", func() {
+	It("should render the PSK secret and the Submariner resource without applying anything", func() {
+		kubeClient := fake.NewSimpleClientset()
+		clientProducer := &client.DefaultProducer{
+			KubeClient:    kubeClient,
+			GeneralClient: fakeClient.NewClientBuilder().Build(),
+		}
+
+		output := &bytes.Buffer{}
+		options := newTestSubmarinerOptions()
+		options.DryRun = true
+		options.DryRunOutput = output
+		brokerInfo, brokerSecret := newTestBrokerInfo()
+
+		submariner, err := deploy.Submariner(context.TODO(), clientProducer, options, brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", "", nil), reporter.Silent())
+		Expect(err).To(Succeed())
+		Expect(submariner).To(BeNil())
+
+		Expect(output.String()).To(ContainSubstring("kind: Secret\n"))
+		Expect(output.String()).To(ContainSubstring("kind: Submariner\n"))
+		Expect(output.String()).ToNot(ContainSubstring(testPSK))
+		Expect(output.String()).ToNot(ContainSubstring(testToken))
+
+		secrets, err := kubeClient.CoreV1().Secrets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		Expect(err).To(Succeed())
+		Expect(secrets.Items).To(BeEmpty())
+	})
+})
@@ -0,0 +1,79 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// maxSecretNameSuffixLength leaves room in the generated names for the base name and the random part added by the API server.
+const maxSecretNameSuffixLength = 30
+
+// ValidateSecretNameSuffix checks that the given suffix can be appended to the names of the secrets created for Submariner.
+func ValidateSecretNameSuffix(suffix string) error {
+	if suffix == "" {
+		return nil
+	}
+
+	if errs := validation.IsDNS1123Label(suffix); len(errs) > 0 {
+		return fmt.Errorf("invalid secret name suffix %q: %s", suffix, strings.Join(errs, ", "))
+	}
+
+	if len(suffix) > maxSecretNameSuffixLength {
+		return fmt.Errorf("the secret name suffix %q is longer than %d characters", suffix, maxSecretNameSuffixLength)
+	}
+
+	return nil
+}
+
+// ValidateSecretMetadata checks that the given labels and annotations, to be added to the secrets created for Submariner,
+// are valid.
+func ValidateSecretMetadata(labels, annotations map[string]string) error {
+	errs := metav1validation.ValidateLabels(labels, field.NewPath("labels"))
+	errs = append(errs, apivalidation.ValidateAnnotations(annotations, field.NewPath("annotations"))...)
+
+	return errs.ToAggregate() //nolint:wrapcheck // No need to wrap errors here.
+}
+
+// WithSecretNameSuffix returns a copy of the given secret with the suffix appended to its name, or to its generated name
+// prefix. The secret is returned unchanged if the suffix is empty.
+func WithSecretNameSuffix(secret *v1.Secret, suffix string) *v1.Secret {
+	if suffix == "" {
+		return secret
+	}
+
+	suffixed := secret.DeepCopy()
+
+	if suffixed.Name != "" {
+		suffixed.Name += "-" + suffix
+	}
+
+	if suffixed.GenerateName != "" {
+		suffixed.GenerateName = strings.TrimSuffix(suffixed.GenerateName, "-") + "-" + suffix + "-"
+	}
+
+	return suffixed
+}
@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ValidateSecretNameSuffix", func() {
+	It("should accept an empty suffix", func() {
+		Expect(deploy.ValidateSecretNameSuffix("")).To(Succeed())
+	})
+
+	It("should accept a DNS label", func() {
+		Expect(deploy.ValidateSecretNameSuffix("east-1")).To(Succeed())
+	})
+
+	It("should reject an invalid suffix", func() {
+		Expect(deploy.ValidateSecretNameSuffix("East_1")).ToNot(Succeed())
+	})
+
+	It("should reject an overly long suffix", func() {
+		Expect(deploy.ValidateSecretNameSuffix(strings.Repeat("a", 31))).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("WithSecretNameSuffix", func() {
+	It("should suffix the name", func() {
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "submariner-ipsec-psk"}}
+
+		Expect(deploy.WithSecretNameSuffix(secret, "east").Name).To(Equal("submariner-ipsec-psk-east"))
+		Expect(secret.Name).To(Equal("submariner-ipsec-psk"))
+	})
+
+	It("should suffix the generated name prefix", func() {
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{GenerateName: "broker-secret-"}}
+
+		Expect(deploy.WithSecretNameSuffix(secret, "east").GenerateName).To(Equal("broker-secret-east-"))
+	})
+
+	It("should leave the secret unchanged without a suffix", func() {
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "submariner-ipsec-psk"}}
+
+		Expect(deploy.WithSecretNameSuffix(secret, "")).To(BeIdenticalTo(secret))
+	})
+})
+
+var _ = Describe("ValidateSecretMetadata", func() {
+	It("should accept valid labels and annotations", func() {
+		Expect(deploy.ValidateSecretMetadata(map[string]string{"example.com/cost-center": "1234"},
+			map[string]string{"owner": "Network team"})).To(Succeed())
+	})
+
+	It("should accept no labels or annotations", func() {
+		Expect(deploy.ValidateSecretMetadata(nil, nil)).To(Succeed())
+	})
+
+	It("should reject invalid labels", func() {
+		Expect(deploy.ValidateSecretMetadata(map[string]string{"owner": "Network team"}, nil)).ToNot(Succeed())
+	})
+})
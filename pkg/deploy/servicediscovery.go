@@ -45,9 +45,14 @@ type ServiceDiscoveryOptions struct {
 func ServiceDiscovery(ctx context.Context, clientProducer client.Producer, options *ServiceDiscoveryOptions, brokerInfo *broker.Info,
 	brokerSecret *v1.Secret, repositoryInfo *image.RepositoryInfo, status reporter.Interface,
 ) error {
-	serviceDiscoverySpec := populateServiceDiscoverySpec(options, brokerInfo, brokerSecret, repositoryInfo)
+	warnIfBrokerInsecure(options.BrokerK8sInsecure, status)
 
-	err := servicediscoverycr.Ensure(ctx, clientProducer.ForGeneral(), constants.OperatorNamespace, serviceDiscoverySpec)
+	serviceDiscoverySpec, err := populateServiceDiscoverySpec(options, brokerInfo, brokerSecret, repositoryInfo)
+	if err != nil {
+		return status.Error(err, "Invalid service discovery configuration")
+	}
+
+	err = servicediscoverycr.Ensure(ctx, clientProducer.ForGeneral(), constants.OperatorNamespace, serviceDiscoverySpec)
 	if err != nil {
 		return status.Error(err, "Service discovery deployment failed")
 	}
@@ -57,7 +62,7 @@ func ServiceDiscovery(ctx context.Context, clientProducer client.Producer, optio
 
 func populateServiceDiscoverySpec(options *ServiceDiscoveryOptions, brokerInfo *broker.Info, brokerSecret *v1.Secret,
 	repositoryInfo *image.RepositoryInfo,
-) *operatorv1alpha1.ServiceDiscoverySpec {
+) (*operatorv1alpha1.ServiceDiscoverySpec, error) {
 	brokerURL := removeSchemaPrefix(brokerInfo.BrokerURL)
 
 	serviceDiscoverySpec := operatorv1alpha1.ServiceDiscoverySpec{
@@ -76,16 +81,17 @@ func populateServiceDiscoverySpec(options *ServiceDiscoveryOptions, brokerInfo *
 	}
 
 	if options.CoreDNSCustomConfigMap != "" {
-		namespace, name := getCustomCoreDNSParams(options.CoreDNSCustomConfigMap)
-		serviceDiscoverySpec.CoreDNSCustomConfig = &operatorv1alpha1.CoreDNSCustomConfig{
-			ConfigMapName: name,
-			Namespace:     namespace,
+		coreDNSRef, err := ParseCoreDNSRef(options.CoreDNSCustomConfigMap)
+		if err != nil {
+			return nil, err
 		}
+
+		serviceDiscoverySpec.CoreDNSCustomConfig = coreDNSRef.toCoreDNSCustomConfig()
 	}
 
 	if len(options.CustomDomains) > 0 {
 		serviceDiscoverySpec.CustomDomains = options.CustomDomains
 	}
 
-	return &serviceDiscoverySpec
+	return &serviceDiscoverySpec, nil
 }
@@ -20,9 +20,22 @@ package deploy
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/coreos/go-semver/semver"
+	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/constants"
 	"github.com/submariner-io/subctl/pkg/broker"
@@ -30,9 +43,25 @@ import (
 	"github.com/submariner-io/subctl/pkg/image"
 	"github.com/submariner-io/subctl/pkg/secret"
 	"github.com/submariner-io/subctl/pkg/submarinercr"
+	"github.com/submariner-io/subctl/pkg/version"
 	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/names"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	invalidLabelValueChars = regexp.MustCompile("[^A-Za-z0-9_.-]+")
+
+	// secretsOnlyVersion is the first version which no longer needs the inline broker connection fields.
+	secretsOnlyVersion = *semver.New("0.13.0")
 )
 
 type SubmarinerOptions struct {
@@ -45,9 +74,25 @@ type SubmarinerOptions struct {
 	LoadBalancerEnabled           bool
 	HealthCheckEnabled            bool
 	BrokerK8sInsecure             bool
+	VerifyKernelModules           bool
+	VerifyKubeProxyMode           bool
+	CustomDomainsMerge            bool
+	OmitInlineBrokerFields        bool
+	CreateOnly                    bool
+	ImageOverridesMerge           bool
+	AutoGenerateClusterID         bool
+	StrictHealthCheck             bool
+	WaitForOperator               bool
+	WaitForReady                  bool
+	DryRun                        bool
+	VerifyReferencedObjects       bool
 	NATTPort                      int
+	GatewayCount                  int
 	HealthCheckInterval           uint64
 	HealthCheckMaxPacketLossCount uint64
+	RetryBudget                   time.Duration
+	OperatorWaitTimeout           time.Duration
+	ReadyWaitTimeout              time.Duration
 	ClusterID                     string
 	CableDriver                   string
 	CoreDNSCustomConfigMap        string
@@ -55,42 +100,553 @@ type SubmarinerOptions struct {
 	ImageVersion                  string
 	ServiceCIDR                   string
 	ClusterCIDR                   string
+	Namespace                     string
+	LighthouseImageOverride       string
+	CustomDomainsRoot             string
+	PSKExternalSecretStore        string
+	PSKExternalSecretKey          string
+	ExistingPSKSecret             string
+	SecretNameSuffix              string
 	CustomDomains                 []string
+	// RawImageOverrides, if set, replace the image overrides entirely; they are passed through verbatim, bypassing the
+	// usual repository and version logic.
+	RawImageOverrides map[string]string
+	// SecretLabels and SecretAnnotations are added to the secrets created for Submariner, e.g. to satisfy admission
+	// policies.
+	SecretLabels      map[string]string
+	SecretAnnotations map[string]string
+	// DryRunOutput receives the rendered resources in dry-run mode; it defaults to stdout.
+	DryRunOutput io.Writer `json:"-"`
+	// VersionResolver, if set, is used to resolve image versions which aren't semantic versions (e.g. "stable") as
+	// version channels.
+	VersionResolver VersionResolver `json:"-"`
+}
+
+// namespace returns the namespace in which Submariner is deployed, the operator namespace unless specified. The operator
+// only watches its own namespace, so it must be deployed in the same namespace.
+func (o *SubmarinerOptions) namespace() string {
+	if o.Namespace != "" {
+		return o.Namespace
+	}
+
+	return constants.OperatorNamespace
+}
+
+// checkNamespaceAvailable verifies that the given namespace doesn't already host the Submariner instance of another
+// cluster.
+func checkNamespaceAvailable(ctx context.Context, client controllerClient.Client, namespace, clusterID string) error {
+	existingSpec, err := GetSubmarinerSpec(ctx, client, namespace)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if existingSpec.ClusterID != clusterID {
+		return fmt.Errorf("the namespace already hosts the Submariner instance of cluster %q", existingSpec.ClusterID)
+	}
+
+	return nil
+}
+
+// Fingerprint returns a hash of the options, which is the same for semantically equal options: the order of elements in
+// lists is irrelevant. The VersionResolver isn't taken into account.
+func (o *SubmarinerOptions) Fingerprint() string {
+	canonical := *o
+	canonical.CustomDomains = sortedCopy(o.CustomDomains)
+
+	// Marshalling can't fail, the options only contain basic types
+	data, _ := json.Marshal(&canonical)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
 }
 
+func sortedCopy(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// VersionResolver resolves a version channel to a concrete version.
+type VersionResolver func(channel string) (string, error)
+
+// ChannelMap is a static mapping from version channels to versions; its Resolve method can be used as a VersionResolver.
+type ChannelMap map[string]string
+
+func (c ChannelMap) Resolve(channel string) (string, error) {
+	if version, ok := c[channel]; ok {
+		return version, nil
+	}
+
+	channels := make([]string, 0, len(c))
+	for known := range c {
+		channels = append(channels, known)
+	}
+
+	sort.Strings(channels)
+
+	return "", fmt.Errorf("unknown version channel %q, the known channels are %q", channel, channels)
+}
+
+// Submariner deploys the Submariner resource, and returns it as applied (nil in dry-run mode). If options.RetryBudget is set,
+// the whole deployment is retried with a jittered backoff, for up to that duration, as long as the cluster's API server is
+// unavailable.
 func Submariner(ctx context.Context, clientProducer client.Producer, options *SubmarinerOptions, brokerInfo *broker.Info,
 	brokerSecret *v1.Secret, netconfig globalnet.Config, repositoryInfo *image.RepositoryInfo, status reporter.Interface,
-) error {
-	pskSecret, err := secret.Ensure(ctx, clientProducer.ForKubernetes(), constants.OperatorNamespace, brokerInfo.IPSecPSK)
+) (*operatorv1alpha1.Submariner, error) {
+	if options.RetryBudget <= 0 {
+		return deploySubmariner(ctx, clientProducer, options, brokerInfo, brokerSecret, netconfig, repositoryInfo, status)
+	}
+
+	backoff := retryBackoff
+	deadline := time.Now().Add(options.RetryBudget)
+
+	for attempt := 1; ; attempt++ {
+		submariner, err := deploySubmariner(ctx, clientProducer, options, brokerInfo, brokerSecret, netconfig, repositoryInfo, status)
+		if err == nil || !isAPIUnavailable(err) {
+			return submariner, err
+		}
+
+		delay := backoff.Step()
+		if time.Now().Add(delay).After(deadline) {
+			return nil, errors.Wrapf(err, "the cluster was still unavailable after %d attempts", attempt)
+		}
+
+		status.Warning("The cluster appears to be unavailable (attempt %d), retrying in %v", attempt, delay.Round(time.Second))
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "deployment cancelled")
+		case <-time.After(delay):
+		}
+	}
+}
+
+func deploySubmariner(ctx context.Context, clientProducer client.Producer, options *SubmarinerOptions, brokerInfo *broker.Info,
+	brokerSecret *v1.Secret, netconfig globalnet.Config, repositoryInfo *image.RepositoryInfo, status reporter.Interface,
+) (*operatorv1alpha1.Submariner, error) {
+	if options.ClusterID == "" && options.AutoGenerateClusterID {
+		clusterID, err := GenerateClusterID(ctx, clientProducer.ForGeneral())
+		if err != nil {
+			return nil, status.Error(err, "Error generating a cluster ID")
+		}
+
+		status.Success("Using the generated cluster ID %q", clusterID)
+
+		withClusterID := *options
+		withClusterID.ClusterID = clusterID
+		options = &withClusterID
+	}
+
+	if options.VerifyKernelModules && !options.DryRun {
+		status.Start("Checking the kernel modules required by the %q cable driver", options.CableDriver)
+
+		err := VerifyKernelModules(ctx, clientProducer.ForKubernetes(), options.namespace(), options.CableDriver, repositoryInfo)
+		if err != nil {
+			return nil, status.Error(err, "Kernel module check failed")
+		}
+
+		status.End()
+	}
+
+	if options.WaitForOperator && !options.DryRun {
+		status.Start("Waiting for the Submariner operator to be available")
+
+		err := awaitOperator(ctx, clientProducer, options.namespace(), options.OperatorWaitTimeout)
+		if err != nil {
+			return nil, status.Error(err, "The Submariner operator isn't available")
+		}
+
+		status.End()
+	}
+
+	warnIfBrokerInsecure(options.BrokerK8sInsecure, status)
+
+	if options.VerifyKubeProxyMode && !options.DryRun {
+		checkKubeProxyMode(ctx, clientProducer.ForKubernetes(), status)
+	}
+
+	if options.GatewayCount > 0 {
+		checkGatewayCount(ctx, clientProducer.ForKubernetes(), options.GatewayCount, status)
+	}
+
+	if options.ServiceCIDR != "" {
+		checkServiceCIDR(ctx, clientProducer.ForGeneral(), options.namespace(), options.ServiceCIDR, status)
+	}
+
+	namespace := options.namespace()
+
+	if options.Namespace != "" {
+		err := checkNamespaceAvailable(ctx, clientProducer.ForGeneral(), namespace, options.ClusterID)
+		if err != nil {
+			return nil, status.Error(err, "Unable to deploy Submariner in namespace %q", namespace)
+		}
+	}
+
+	err := ValidateSecretNameSuffix(options.SecretNameSuffix)
+	if err == nil {
+		err = ValidateSecretMetadata(options.SecretLabels, options.SecretAnnotations)
+	}
+
+	if err == nil && options.ExistingPSKSecret != "" && options.PSKExternalSecretStore != "" {
+		err = errors.New("an existing PSK secret and a PSK external secret store can't both be specified")
+	}
+
 	if err != nil {
-		return status.Error(err, "Error creating PSK secret for cluster")
+		return nil, status.Error(err, "Invalid Submariner configuration")
 	}
 
-	submarinerSpec := populateSubmarinerSpec(options, brokerInfo, brokerSecret, pskSecret, netconfig, repositoryInfo)
+	var pskSecret *v1.Secret
+
+	if options.DryRun {
+		pskSecret, err = pskSecretFor(options, brokerInfo)
+	} else if options.ExistingPSKSecret != "" {
+		pskSecret, err = getExistingPSKSecret(ctx, clientProducer.ForKubernetes(), namespace, options.ExistingPSKSecret)
+	} else if options.PSKExternalSecretStore != "" {
+		pskSecret, err = brokerPSKSecret(options, brokerInfo)
+		if err == nil {
+			pskSecret, err = ensurePSKExternalSecret(ctx, clientProducer.ForDynamic(), options, namespace, pskSecret.Name)
+		}
+	} else {
+		pskSecret, err = brokerPSKSecret(options, brokerInfo)
+		if err == nil {
+			pskSecret, err = secret.Ensure(ctx, clientProducer.ForKubernetes(), namespace, pskSecret,
+				withVersionLabels(options.SecretLabels), deployedByAnnotations(options.SecretAnnotations))
+		}
+	}
 
-	err = submarinercr.Ensure(ctx, clientProducer.ForGeneral(), constants.OperatorNamespace, submarinerSpec)
 	if err != nil {
-		return status.Error(err, "Submariner deployment failed")
+		return nil, status.Error(err, "Error creating PSK secret for cluster")
 	}
 
-	return nil
+	submarinerSpec, err := populateSubmarinerSpec(options, brokerInfo, brokerSecret, pskSecret, netconfig, repositoryInfo)
+	if err != nil {
+		return nil, status.Error(err, "Invalid Submariner configuration")
+	}
+
+	if !options.StrictHealthCheck {
+		err = checkHealthCheckDetectionTime(options)
+		if err != nil {
+			status.Warning("The connection health check is ineffective: %s", err)
+		}
+	}
+
+	if options.OmitInlineBrokerFields {
+		if inlineBrokerFieldsObsolete(submarinerSpec.Version) {
+			status.Success("The deprecated inline broker connection fields are omitted, the broker and PSK secrets are used instead")
+		} else {
+			status.Warning("Version %q may still require the deprecated inline broker connection fields, they are kept",
+				submarinerSpec.Version)
+		}
+	}
+
+	if options.CustomDomainsMerge || options.ImageOverridesMerge {
+		existingSpec, err := GetSubmarinerSpec(ctx, clientProducer.ForGeneral(), namespace)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, status.Error(err, "Error retrieving the existing Submariner configuration")
+		}
+
+		if existingSpec != nil && options.CustomDomainsMerge {
+			submarinerSpec.CustomDomains = MergeCustomDomains(existingSpec.CustomDomains, submarinerSpec.CustomDomains)
+		}
+
+		if existingSpec != nil && options.ImageOverridesMerge {
+			submarinerSpec.ImageOverrides = MergeImageOverrides(existingSpec.ImageOverrides, submarinerSpec.ImageOverrides)
+		}
+	}
+
+	if options.DryRun {
+		return nil, renderDryRun(options, pskSecret, submarinerSpec)
+	}
+
+	if options.VerifyReferencedObjects {
+		err = checkReferencedObjects(ctx, clientProducer.ForKubernetes(), submarinerSpec, options.PSKExternalSecretStore != "")
+		if err != nil {
+			return nil, status.Error(err, "Invalid Submariner configuration")
+		}
+	}
+
+	var submariner *operatorv1alpha1.Submariner
+
+	if options.CreateOnly {
+		submariner, err = submarinercr.Create(ctx, clientProducer.ForGeneral(), namespace, submarinerSpec, versionLabels(),
+			deployedByAnnotations(nil))
+	} else {
+		submariner, err = submarinercr.Ensure(ctx, clientProducer.ForGeneral(), namespace, submarinerSpec, versionLabels(),
+			deployedByAnnotations(nil))
+	}
+
+	if err != nil {
+		return nil, status.Error(err, "Submariner deployment failed")
+	}
+
+	if options.WaitForReady {
+		err = WaitForSubmarinerReady(ctx, clientProducer, namespace, options.ReadyWaitTimeout, status)
+		if err != nil {
+			return submariner, err
+		}
+	}
+
+	return submariner, nil
+}
+
+// getExistingPSKSecret retrieves the named PSK secret, which is managed outside subctl, and verifies that it contains a PSK.
+func getExistingPSKSecret(ctx context.Context, kubeClient kubernetes.Interface, namespace, name string) (*v1.Secret, error) {
+	pskSecret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving the existing PSK secret %s/%s", namespace, name)
+	}
+
+	if len(pskSecret.Data["psk"]) == 0 {
+		return nil, fmt.Errorf("the existing PSK secret %s/%s doesn't contain a %q key", namespace, name, "psk")
+	}
+
+	return pskSecret, nil
+}
+
+// pskSecretFor returns the PSK secret referenced by the Submariner resource deployed with the given options; its contents
+// are only meaningful when subctl manages the secret.
+func pskSecretFor(options *SubmarinerOptions, brokerInfo *broker.Info) (*v1.Secret, error) {
+	if options.ExistingPSKSecret != "" {
+		return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: options.ExistingPSKSecret, Namespace: options.namespace()}}, nil
+	}
+
+	return brokerPSKSecret(options, brokerInfo)
+}
+
+// brokerPSKSecret returns the broker's PSK secret, named with the configured suffix.
+func brokerPSKSecret(options *SubmarinerOptions, brokerInfo *broker.Info) (*v1.Secret, error) {
+	if brokerInfo.IPSecPSK == nil {
+		return nil, errors.New("the broker information doesn't contain an IPsec PSK")
+	}
+
+	return WithSecretNameSuffix(brokerInfo.IPSecPSK, options.SecretNameSuffix), nil
+}
+
+// GetSubmarinerSpec retrieves the Submariner resource deployed in the given namespace and returns a copy of its spec,
+// which can be modified and written back using ApplySubmarinerSpec.
+func GetSubmarinerSpec(ctx context.Context, client controllerClient.Client, namespace string,
+) (*operatorv1alpha1.SubmarinerSpec, error) {
+	submariner := &operatorv1alpha1.Submariner{}
+
+	err := client.Get(ctx, controllerClient.ObjectKey{Namespace: namespace, Name: names.SubmarinerCrName}, submariner)
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving Submariner resource")
+	}
+
+	return submariner.Spec.DeepCopy(), nil
+}
+
+// ApplySubmarinerSpec writes the given spec to the Submariner resource in the given namespace.
+func ApplySubmarinerSpec(ctx context.Context, client controllerClient.Client, namespace string,
+	submarinerSpec *operatorv1alpha1.SubmarinerSpec,
+) error {
+	_, err := submarinercr.Ensure(ctx, client, namespace, submarinerSpec, versionLabels(), deployedByAnnotations(nil))
+
+	return err //nolint:wrapcheck // No need to wrap errors here.
+}
+
+// OptionsFromSpec reconstructs the options which produce the given Submariner spec, so that a deployed configuration can be
+// tweaked and redeployed. The PSK secret, if given, must be the one referenced by the spec. A PSK secret named as subctl
+// names the secrets it creates determines the secret name suffix; any other PSK secret is an existing one.
+//
+// Not everything round-trips. The broker connection fields and the global CIDR come from the broker information and the
+// globalnet configuration, so they aren't part of the options. The Lighthouse image override is folded into the raw image
+// overrides. Settings that only affect how subctl deploys (the PSK external secret store, the custom domains root, waiting
+// for the operator or the components, merging, and the preflight checks) aren't recorded in the spec and are left at their
+// defaults.
+func OptionsFromSpec(spec *operatorv1alpha1.SubmarinerSpec, pskSecret *v1.Secret) (*SubmarinerOptions, error) {
+	if spec == nil {
+		return nil, errors.New("no Submariner spec provided")
+	}
+
+	options := &SubmarinerOptions{
+		PreferredServer:        spec.CeIPSecPreferredServer,
+		ForceUDPEncaps:         spec.CeIPSecForceUDPEncaps,
+		NATTraversal:           spec.NatEnabled,
+		IPSecDebug:             spec.CeIPSecDebug,
+		SubmarinerDebug:        spec.Debug,
+		AirGappedDeployment:    spec.AirGappedDeployment,
+		LoadBalancerEnabled:    spec.LoadBalancerEnabled,
+		BrokerK8sInsecure:      spec.BrokerK8sInsecure,
+		OmitInlineBrokerFields: spec.BrokerK8sApiServerToken == "" && spec.BrokerK8sSecret != "",
+		NATTPort:               spec.CeIPSecNATTPort,
+		ClusterID:              spec.ClusterID,
+		CableDriver:            spec.CableDriver,
+		Repository:             spec.Repository,
+		ImageVersion:           spec.Version,
+		ServiceCIDR:            spec.ServiceCIDR,
+		ClusterCIDR:            spec.ClusterCIDR,
+		Namespace:              spec.Namespace,
+		CustomDomains:          append([]string(nil), spec.CustomDomains...),
+	}
+
+	if spec.ConnectionHealthCheck != nil {
+		options.HealthCheckEnabled = spec.ConnectionHealthCheck.Enabled
+		options.HealthCheckInterval = spec.ConnectionHealthCheck.IntervalSeconds
+		options.HealthCheckMaxPacketLossCount = spec.ConnectionHealthCheck.MaxPacketLossCount
+	}
+
+	if spec.CoreDNSCustomConfig != nil {
+		options.CoreDNSCustomConfigMap = spec.CoreDNSCustomConfig.Namespace + "/" + spec.CoreDNSCustomConfig.ConfigMapName
+	}
+
+	if len(spec.ImageOverrides) > 0 {
+		options.RawImageOverrides = make(map[string]string, len(spec.ImageOverrides))
+		for component, image := range spec.ImageOverrides {
+			options.RawImageOverrides[component] = image
+		}
+	}
+
+	if pskSecret != nil && pskSecret.Name != spec.CeIPSecPSKSecret {
+		return nil, fmt.Errorf("the PSK secret %q isn't the one referenced by the Submariner spec, %q", pskSecret.Name,
+			spec.CeIPSecPSKSecret)
+	}
+
+	switch {
+	case spec.CeIPSecPSKSecret == "", spec.CeIPSecPSKSecret == broker.IPSecPSKSecretName:
+		// No PSK secret, or one created by subctl without a suffix
+	case strings.HasPrefix(spec.CeIPSecPSKSecret, broker.IPSecPSKSecretName+"-"):
+		options.SecretNameSuffix = strings.TrimPrefix(spec.CeIPSecPSKSecret, broker.IPSecPSKSecretName+"-")
+	default:
+		options.ExistingPSKSecret = spec.CeIPSecPSKSecret
+	}
+
+	return options, nil
+}
+
+// MergeCustomDomains returns the existing custom domains followed by the requested ones which aren't already present.
+func MergeCustomDomains(existing, requested []string) []string {
+	merged := make([]string, 0, len(existing)+len(requested))
+	seen := map[string]bool{}
+
+	for _, domain := range append(append([]string{}, existing...), requested...) {
+		if !seen[domain] {
+			seen[domain] = true
+			merged = append(merged, domain)
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	return merged
+}
+
+// MergeImageOverrides returns the existing image overrides updated with the requested ones, which take precedence.
+func MergeImageOverrides(existing, requested map[string]string) map[string]string {
+	if len(existing) == 0 && len(requested) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string, len(existing)+len(requested))
+
+	for component, imageURL := range existing {
+		merged[component] = imageURL
+	}
+
+	for component, imageURL := range requested {
+		merged[component] = imageURL
+	}
+
+	return merged
+}
+
+// versionLabels returns the labels identifying the subctl version which deployed a resource. Build versions may contain
+// characters which aren't allowed in label values, those are replaced.
+func versionLabels() map[string]string {
+	value := invalidLabelValueChars.ReplaceAllString(version.Version, "-")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+
+	return map[string]string{constants.SubctlVersionLabel: strings.Trim(value, "-_.")}
+}
+
+// withVersionLabels returns the given labels along with the subctl version label.
+func withVersionLabels(labels map[string]string) map[string]string {
+	withVersion := versionLabels()
+	for key, value := range labels {
+		withVersion[key] = value
+	}
+
+	return withVersion
+}
+
+// deployedByAnnotations returns the given annotations along with one recording the exact version of subctl deploying
+// the resource; unlike the version label, the annotation isn't constrained to label value syntax.
+func deployedByAnnotations(annotations map[string]string) map[string]string {
+	withVersion := make(map[string]string, len(annotations)+1)
+	for key, value := range annotations {
+		withVersion[key] = value
+	}
+
+	withVersion[constants.SubctlDeployedByVersionAnnotation] = version.Version
+
+	return withVersion
+}
+
+var retryBackoff = wait.Backoff{
+	Duration: 2 * time.Second,
+	Factor:   2,
+	Jitter:   0.5,
+	Steps:    math.MaxInt32,
+	Cap:      time.Minute,
+}
+
+// isAPIUnavailable determines whether the given error indicates that the API server couldn't be reached or couldn't
+// serve the request, as opposed to a request that failed on its merits. Network errors are only considered transient
+// if they are timeouts or refused or reset connections; others, such as unknown hosts or certificate errors, won't go
+// away by retrying.
+func isAPIUnavailable(err error) bool {
+	if apierrors.IsServiceUnavailable(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsInternalError(err) {
+		return true
+	}
+
+	if utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return goerrors.As(err, &netErr) && netErr.Timeout()
 }
 
 func populateSubmarinerSpec(options *SubmarinerOptions, brokerInfo *broker.Info, brokerSecret *v1.Secret, pskSecret *v1.Secret,
 	netconfig globalnet.Config, repositoryInfo *image.RepositoryInfo,
-) *operatorv1alpha1.SubmarinerSpec {
+) (*operatorv1alpha1.SubmarinerSpec, error) {
 	brokerURL := removeSchemaPrefix(brokerInfo.BrokerURL)
 
+	version, err := resolveVersion(repositoryInfo.Version, options.VersionResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	var psk []byte
+	if brokerInfo.IPSecPSK != nil {
+		psk = brokerInfo.IPSecPSK.Data["psk"]
+	}
+
 	// For backwards compatibility, the connection information is populated through the secret and individual components
 	// TODO skitt This will be removed in the release following 0.12
 	submarinerSpec := &operatorv1alpha1.SubmarinerSpec{
 		Repository:               repositoryInfo.Name,
-		Version:                  repositoryInfo.Version,
+		Version:                  version,
 		CeIPSecNATTPort:          options.NATTPort,
 		CeIPSecDebug:             options.IPSecDebug,
 		CeIPSecForceUDPEncaps:    options.ForceUDPEncaps,
 		CeIPSecPreferredServer:   options.PreferredServer,
-		CeIPSecPSK:               base64.StdEncoding.EncodeToString(brokerInfo.IPSecPSK.Data["psk"]),
+		CeIPSecPSK:               base64.StdEncoding.EncodeToString(psk),
 		CeIPSecPSKSecret:         pskSecret.ObjectMeta.Name,
 		BrokerK8sCA:              base64.StdEncoding.EncodeToString(brokerSecret.Data["ca.crt"]),
 		BrokerK8sRemoteNamespace: string(brokerSecret.Data["namespace"]),
@@ -104,7 +660,7 @@ func populateSubmarinerSpec(options *SubmarinerOptions, brokerInfo *broker.Info,
 		ClusterID:                options.ClusterID,
 		ServiceCIDR:              options.ServiceCIDR,
 		ClusterCIDR:              options.ClusterCIDR,
-		Namespace:                constants.OperatorNamespace,
+		Namespace:                options.namespace(),
 		CableDriver:              options.CableDriver,
 		ServiceDiscoveryEnabled:  brokerInfo.IsServiceDiscoveryEnabled(),
 		ImageOverrides:           repositoryInfo.Overrides,
@@ -116,15 +672,78 @@ func populateSubmarinerSpec(options *SubmarinerOptions, brokerInfo *broker.Info,
 			MaxPacketLossCount: options.HealthCheckMaxPacketLossCount,
 		},
 	}
+	if options.ExistingPSKSecret != "" {
+		// The PSK is managed outside subctl, the one from the broker information mustn't be embedded
+		submarinerSpec.CeIPSecPSK = ""
+	}
+
+	if options.PSKExternalSecretStore != "" {
+		// The PSK is provided by the external secret store, it must not be embedded
+		submarinerSpec.CeIPSecPSK = ""
+
+		_, err = newPSKExternalSecret(options, options.namespace(), pskSecret.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.OmitInlineBrokerFields && inlineBrokerFieldsObsolete(version) {
+		submarinerSpec.CeIPSecPSK = ""
+		submarinerSpec.BrokerK8sCA = ""
+		submarinerSpec.BrokerK8sApiServerToken = ""
+	}
+
+	err = checkHealthCheckInterval(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.StrictHealthCheck {
+		err = checkHealthCheckDetectionTime(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = checkCIDRs(options.ServiceCIDR, options.ClusterCIDR, netconfig.GlobalCIDR)
+	if err != nil {
+		return nil, err
+	}
+
 	if netconfig.GlobalCIDR != "" {
 		submarinerSpec.GlobalCIDR = netconfig.GlobalCIDR
 	}
 
+	if options.RawImageOverrides != nil {
+		err = checkImageReferences(options.RawImageOverrides)
+		if err != nil {
+			return nil, err
+		}
+
+		submarinerSpec.ImageOverrides = options.RawImageOverrides
+	}
+
+	if options.LighthouseImageOverride != "" {
+		submarinerSpec.ImageOverrides, err = withLighthouseImageOverride(submarinerSpec.ImageOverrides, options.LighthouseImageOverride,
+			submarinerSpec.ServiceDiscoveryEnabled)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if options.CoreDNSCustomConfigMap != "" {
-		namespace, name := getCustomCoreDNSParams(options.CoreDNSCustomConfigMap)
-		submarinerSpec.CoreDNSCustomConfig = &operatorv1alpha1.CoreDNSCustomConfig{
-			ConfigMapName: name,
-			Namespace:     namespace,
+		coreDNSRef, err := ParseCoreDNSRef(options.CoreDNSCustomConfigMap)
+		if err != nil {
+			return nil, err
+		}
+
+		submarinerSpec.CoreDNSCustomConfig = coreDNSRef.toCoreDNSCustomConfig()
+	}
+
+	if options.CustomDomainsRoot != "" {
+		err = checkCustomDomainsRoot(options.CustomDomains, options.CustomDomainsRoot)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -132,21 +751,32 @@ func populateSubmarinerSpec(options *SubmarinerOptions, brokerInfo *broker.Info,
 		submarinerSpec.CustomDomains = options.CustomDomains
 	}
 
-	return submarinerSpec
+	return submarinerSpec, nil
+}
+
+// inlineBrokerFieldsObsolete determines whether the given Submariner version only uses the broker and PSK secrets, in
+// which case the inline connection fields are no longer needed. Versions which can't be parsed are assumed to need them.
+func inlineBrokerFieldsObsolete(version string) bool {
+	parsed, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return false
+	}
+
+	return !parsed.LessThan(secretsOnlyVersion)
 }
 
-func getCustomCoreDNSParams(corednsCustomConfigMap string) (namespace, name string) {
-	if corednsCustomConfigMap != "" {
-		name = corednsCustomConfigMap
+func resolveVersion(version string, resolver VersionResolver) (string, error) {
+	if resolver == nil {
+		return version, nil
+	}
 
-		paramList := strings.Split(corednsCustomConfigMap, "/")
-		if len(paramList) > 1 {
-			namespace = paramList[0]
-			name = paramList[1]
-		}
+	if _, err := semver.NewVersion(strings.TrimPrefix(version, "v")); err == nil {
+		return version, nil
 	}
 
-	return namespace, name
+	resolved, err := resolver(version)
+
+	return resolved, errors.Wrap(err, "error resolving the image version")
 }
 
 func removeSchemaPrefix(brokerURL string) string {
@@ -0,0 +1,486 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	"github.com/submariner-io/subctl/pkg/image"
+	"github.com/submariner-io/subctl/pkg/version"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	kubeScheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func renderWith(options *deploy.SubmarinerOptions, repositoryInfo *image.RepositoryInfo) (string, error) {
+	brokerInfo, brokerSecret := newTestBrokerInfo()
+
+	rendered, err := deploy.RenderSubmariner(options, brokerInfo, brokerSecret, globalnet.Config{}, repositoryInfo)
+
+	return string(rendered), err
+}
+
+var _ = Describe("Version channels", func() {
+	channels := deploy.ChannelMap{"stable": "0.14.2", "candidate": "0.15.0-rc1"}
+
+	var options *deploy.SubmarinerOptions
+
+	BeforeEach(func() {
+		options = newTestSubmarinerOptions()
+		options.VersionResolver = channels.Resolve
+	})
+
+	When("the version is a known channel", func() {
+		It("should record the resolved version", func() {
+			output, err := renderWith(options, image.NewRepositoryInfo("", "stable", nil))
+			Expect(err).To(Succeed())
+			Expect(output).To(ContainSubstring("version: 0.14.2"))
+		})
+	})
+
+	When("the version is a semantic version", func() {
+		It("should use it as-is", func() {
+			output, err := renderWith(options, image.NewRepositoryInfo("", "v0.13.1", nil))
+			Expect(err).To(Succeed())
+			Expect(output).To(ContainSubstring("version: v0.13.1"))
+		})
+	})
+
+	When("the version is an unknown channel", func() {
+		It("should return an error listing the known channels", func() {
+			_, err := renderWith(options, image.NewRepositoryInfo("", "nightly", nil))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("nightly"))
+			Expect(err.Error()).To(ContainSubstring(`["candidate" "stable"]`))
+		})
+	})
+})
+
+var _ = Describe("SubmarinerOptions Fingerprint", func() {
+	It("should be the same for equal options", func() {
+		Expect(newTestSubmarinerOptions().Fingerprint()).To(Equal(newTestSubmarinerOptions().Fingerprint()))
+	})
+
+	It("should not depend on the order of the custom domains", func() {
+		reordered := newTestSubmarinerOptions()
+		reordered.CustomDomains = []string{reordered.CustomDomains[1], reordered.CustomDomains[0]}
+
+		Expect(reordered.Fingerprint()).To(Equal(newTestSubmarinerOptions().Fingerprint()))
+	})
+
+	It("should treat no custom domains and an empty list the same", func() {
+		withNil := newTestSubmarinerOptions()
+		withNil.CustomDomains = nil
+
+		withEmpty := newTestSubmarinerOptions()
+		withEmpty.CustomDomains = []string{}
+
+		Expect(withNil.Fingerprint()).To(Equal(withEmpty.Fingerprint()))
+	})
+
+	It("should differ when an option changes", func() {
+		changed := newTestSubmarinerOptions()
+		changed.NATTPort = 4501
+
+		Expect(changed.Fingerprint()).ToNot(Equal(newTestSubmarinerOptions().Fingerprint()))
+	})
+})
+
+var _ = Describe("MergeCustomDomains", func() {
+	It("should add the requested domains to the existing ones without duplicates", func() {
+		Expect(deploy.MergeCustomDomains([]string{"a.example.com", "b.example.com"}, []string{"b.example.com", "c.example.com"})).
+			To(Equal([]string{"a.example.com", "b.example.com", "c.example.com"}))
+	})
+
+	It("should return nil when there are no domains", func() {
+		Expect(deploy.MergeCustomDomains(nil, nil)).To(BeNil())
+	})
+})
+
+var _ = Describe("MergeImageOverrides", func() {
+	It("should add the requested overrides to the existing ones, replacing those for the same components", func() {
+		Expect(deploy.MergeImageOverrides(map[string]string{"submariner-gateway": "gw:old", "submariner-routeagent": "ra:hotfix"},
+			map[string]string{"submariner-gateway": "gw:new"})).
+			To(Equal(map[string]string{"submariner-gateway": "gw:new", "submariner-routeagent": "ra:hotfix"}))
+	})
+
+	It("should return nil when there are no overrides", func() {
+		Expect(deploy.MergeImageOverrides(nil, map[string]string{})).To(BeNil())
+	})
+})
+
+var _ = Describe("OptionsFromSpec", func() {
+	var spec *operatorv1alpha1.SubmarinerSpec
+
+	BeforeEach(func() {
+		options := newTestSubmarinerOptions()
+		options.SecretNameSuffix = "blue"
+		options.HealthCheckEnabled = true
+		options.HealthCheckInterval = 2
+
+		rendered, err := renderWith(options, image.NewRepositoryInfo("", "0.14.2", nil))
+		Expect(err).To(Succeed())
+
+		submariner := &operatorv1alpha1.Submariner{}
+		Expect(yaml.Unmarshal([]byte(rendered), submariner)).To(Succeed())
+
+		spec = &submariner.Spec
+	})
+
+	It("should reconstruct the options used to produce the spec", func() {
+		options, err := deploy.OptionsFromSpec(spec, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "submariner-ipsec-psk-blue"}})
+		Expect(err).To(Succeed())
+
+		expected := newTestSubmarinerOptions()
+		Expect(options.ClusterID).To(Equal(expected.ClusterID))
+		Expect(options.CableDriver).To(Equal(expected.CableDriver))
+		Expect(options.ServiceCIDR).To(Equal(expected.ServiceCIDR))
+		Expect(options.ClusterCIDR).To(Equal(expected.ClusterCIDR))
+		Expect(options.NATTPort).To(Equal(expected.NATTPort))
+		Expect(options.CustomDomains).To(Equal(expected.CustomDomains))
+		Expect(options.ImageVersion).To(Equal("0.14.2"))
+		Expect(options.HealthCheckEnabled).To(BeTrue())
+		Expect(options.HealthCheckInterval).To(Equal(uint64(2)))
+		Expect(options.SecretNameSuffix).To(Equal("blue"))
+		Expect(options.ExistingPSKSecret).To(BeEmpty())
+	})
+
+	When("the spec references an existing PSK secret", func() {
+		It("should reconstruct the existing PSK secret option", func() {
+			options := newTestSubmarinerOptions()
+			options.ExistingPSKSecret = "managed-psk"
+
+			rendered, err := renderWith(options, image.NewRepositoryInfo("", "0.14.2", nil))
+			Expect(err).To(Succeed())
+
+			submariner := &operatorv1alpha1.Submariner{}
+			Expect(yaml.Unmarshal([]byte(rendered), submariner)).To(Succeed())
+
+			reconstructed, err := deploy.OptionsFromSpec(&submariner.Spec,
+				&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "managed-psk"}})
+			Expect(err).To(Succeed())
+			Expect(reconstructed.ExistingPSKSecret).To(Equal("managed-psk"))
+			Expect(reconstructed.SecretNameSuffix).To(BeEmpty())
+		})
+	})
+
+	When("the PSK secret isn't the one referenced by the spec", func() {
+		It("should return an error", func() {
+			_, err := deploy.OptionsFromSpec(spec, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other-psk"}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("no spec is provided", func() {
+		It("should return an error", func() {
+			_, err := deploy.OptionsFromSpec(nil, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Deploying with an existing PSK secret", func() {
+	const pskSecretName = "managed-psk"
+
+	var (
+		kubeClient     *fake.Clientset
+		clientProducer *client.DefaultProducer
+		options        *deploy.SubmarinerOptions
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+
+		kubeClient = fake.NewSimpleClientset()
+		clientProducer = &client.DefaultProducer{
+			KubeClient:    kubeClient,
+			GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).Build(),
+		}
+
+		options = newTestSubmarinerOptions()
+		options.ExistingPSKSecret = pskSecretName
+	})
+
+	deploySubmariner := func() (*operatorv1alpha1.Submariner, error) {
+		brokerInfo, brokerSecret := newTestBrokerInfo()
+
+		return deploy.Submariner(context.TODO(), clientProducer, options, brokerInfo, brokerSecret, globalnet.Config{},
+			image.NewRepositoryInfo("", "", nil), reporter.Silent())
+	}
+
+	createPSKSecret := func(data map[string][]byte) {
+		_, err := kubeClient.CoreV1().Secrets(constants.OperatorNamespace).Create(context.TODO(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: pskSecretName},
+			Data:       data,
+		}, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+	}
+
+	When("the secret contains a PSK", func() {
+		It("should reference it without creating a PSK secret or embedding the PSK", func() {
+			createPSKSecret(map[string][]byte{"psk": []byte("managed")})
+
+			submariner, err := deploySubmariner()
+			Expect(err).To(Succeed())
+			Expect(submariner.Spec.CeIPSecPSKSecret).To(Equal(pskSecretName))
+			Expect(submariner.Spec.CeIPSecPSK).To(BeEmpty())
+
+			secrets, err := kubeClient.CoreV1().Secrets(constants.OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(err).To(Succeed())
+			Expect(secrets.Items).To(HaveLen(1))
+		})
+	})
+
+	When("the secret doesn't contain a PSK", func() {
+		It("should return an error naming the missing key", func() {
+			createPSKSecret(map[string][]byte{"key": []byte("managed")})
+
+			_, err := deploySubmariner()
+			Expect(err).To(MatchError(ContainSubstring(`doesn't contain a "psk" key`)))
+		})
+	})
+
+	When("the secret doesn't exist", func() {
+		It("should return an error", func() {
+			_, err := deploySubmariner()
+			Expect(err).To(MatchError(ContainSubstring(pskSecretName)))
+		})
+	})
+
+	When("a PSK external secret store is also specified", func() {
+		It("should return an error", func() {
+			options.PSKExternalSecretStore = "vault"
+
+			_, err := deploySubmariner()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Redeploying with another version of subctl", func() {
+	var (
+		generalClient controllerClient.Client
+		savedVersion  string
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(operatorv1alpha1.AddToScheme(kubeScheme.Scheme)).To(Succeed())
+
+		generalClient = fakeClient.NewClientBuilder().WithScheme(scheme).Build()
+
+		savedVersion = version.Version
+		DeferCleanup(func() {
+			version.Version = savedVersion
+		})
+	})
+
+	getSubmariner := func() *operatorv1alpha1.Submariner {
+		submariner := &operatorv1alpha1.Submariner{}
+		Expect(generalClient.Get(context.TODO(), controllerClient.ObjectKey{
+			Namespace: constants.OperatorNamespace,
+			Name:      names.SubmarinerCrName,
+		}, submariner)).To(Succeed())
+
+		return submariner
+	}
+
+	It("should update the version metadata without replacing the Submariner resource or clobbering user annotations", func() {
+		version.Version = "v0.15.0"
+
+		Expect(deploy.ApplySubmarinerSpec(context.TODO(), generalClient, constants.OperatorNamespace,
+			&operatorv1alpha1.SubmarinerSpec{ClusterID: "east"})).To(Succeed())
+
+		// The status is only preserved if the resource isn't replaced
+		submariner := getSubmariner()
+		submariner.Annotations["owner"] = "team-a"
+		submariner.Status.ClusterID = "east"
+		Expect(generalClient.Update(context.TODO(), submariner)).To(Succeed())
+
+		spec, err := deploy.GetSubmarinerSpec(context.TODO(), generalClient, constants.OperatorNamespace)
+		Expect(err).To(Succeed())
+
+		version.Version = "v0.15.1"
+
+		Expect(deploy.ApplySubmarinerSpec(context.TODO(), generalClient, constants.OperatorNamespace, spec)).To(Succeed())
+
+		submariner = getSubmariner()
+		Expect(submariner.Status.ClusterID).To(Equal("east"))
+		Expect(submariner.Labels).To(HaveKeyWithValue(constants.SubctlVersionLabel, "v0.15.1"))
+		Expect(submariner.Annotations).To(HaveKeyWithValue(constants.SubctlDeployedByVersionAnnotation, "v0.15.1"))
+		Expect(submariner.Annotations).To(HaveKeyWithValue("owner", "team-a"))
+	})
+})
+
+var _ = Describe("Deploying with broker information lacking an IPsec PSK", func() {
+	var (
+		options    *deploy.SubmarinerOptions
+		brokerInfo *broker.Info
+	)
+
+	BeforeEach(func() {
+		options = newTestSubmarinerOptions()
+		brokerInfo, _ = newTestBrokerInfo()
+		brokerInfo.IPSecPSK = nil
+	})
+
+	deploySubmariner := func() error {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		_, brokerSecret := newTestBrokerInfo()
+
+		_, err := deploy.Submariner(context.TODO(), &client.DefaultProducer{
+			KubeClient:    fake.NewSimpleClientset(),
+			GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).Build(),
+		}, options, brokerInfo, brokerSecret, globalnet.Config{}, image.NewRepositoryInfo("", "", nil), reporter.Silent())
+
+		return err
+	}
+
+	When("the PSK is provided through an ExternalSecret", func() {
+		It("should return an error", func() {
+			options.PSKExternalSecretStore = "ClusterSecretStore/vault"
+			options.PSKExternalSecretKey = "submariner/psk"
+
+			Expect(deploySubmariner()).To(MatchError(ContainSubstring("doesn't contain an IPsec PSK")))
+		})
+	})
+	When("no existing PSK secret is specified", func() {
+		It("should return an error", func() {
+			Expect(deploySubmariner()).To(MatchError(ContainSubstring("doesn't contain an IPsec PSK")))
+		})
+	})
+
+	When("doing a dry run", func() {
+		It("should return an error", func() {
+			options.DryRun = true
+
+			Expect(deploySubmariner()).To(MatchError(ContainSubstring("doesn't contain an IPsec PSK")))
+		})
+	})
+
+	When("an existing PSK secret is specified", func() {
+		It("should not need the broker's PSK", func() {
+			options.ExistingPSKSecret = "managed-psk"
+			options.DryRun = true
+			options.DryRunOutput = io.Discard
+
+			Expect(deploySubmariner()).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Deploying with a retry budget", func() {
+	const pskSecretName = "managed-psk"
+
+	var (
+		kubeClient *fake.Clientset
+		options    *deploy.SubmarinerOptions
+		failures   int
+		failure    error
+		attempts   int
+	)
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: pskSecretName, Namespace: constants.OperatorNamespace},
+			Data:       map[string][]byte{"psk": []byte("managed")},
+		})
+		attempts = 0
+
+		kubeClient.PrependReactor("get", "secrets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			attempts++
+			if attempts <= failures {
+				return true, nil, failure
+			}
+
+			return false, nil, nil
+		})
+
+		options = newTestSubmarinerOptions()
+		options.ExistingPSKSecret = pskSecretName
+		options.RetryBudget = time.Minute
+	})
+
+	deploySubmariner := func() error {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		brokerInfo, brokerSecret := newTestBrokerInfo()
+
+		_, err := deploy.Submariner(context.TODO(), &client.DefaultProducer{
+			KubeClient:    kubeClient,
+			GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).Build(),
+		}, options, brokerInfo, brokerSecret, globalnet.Config{}, image.NewRepositoryInfo("", "", nil), reporter.Silent())
+
+		return err
+	}
+
+	apiRequestError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://api.example.com:6443/api/v1/namespaces", Err: err}
+	}
+
+	When("the API server times out", func() {
+		It("should retry the deployment", func() {
+			failures = 1
+			failure = apiRequestError(&net.DNSError{Err: "i/o timeout", Name: "api.example.com", IsTimeout: true})
+
+			Expect(deploySubmariner()).To(Succeed())
+			Expect(attempts).To(Equal(2))
+		})
+	})
+
+	DescribeTable("permanent network errors",
+		func(err error) {
+			failures = 3
+			failure = apiRequestError(err)
+
+			Expect(deploySubmariner()).ToNot(Succeed())
+			Expect(attempts).To(Equal(1))
+		},
+		Entry("an unknown host", &net.DNSError{Err: "no such host", Name: "api.example.com", IsNotFound: true}),
+		Entry("an untrusted certificate", x509.UnknownAuthorityError{}),
+		Entry("an invalid certificate", x509.HostnameError{Certificate: &x509.Certificate{}, Host: "api.example.com"}),
+	)
+})
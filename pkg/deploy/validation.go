@@ -0,0 +1,193 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+)
+
+const (
+	// maxHealthCheckDetectionTime is the longest acceptable delay before an unhealthy connection is detected.
+	maxHealthCheckDetectionTime = 5 * time.Minute
+	// maxHealthCheckInterval is the longest accepted health check interval, in seconds; beyond that, connection failures
+	// would go unnoticed for too long whatever the packet loss count.
+	maxHealthCheckInterval = 300
+)
+
+// imageReference matches image references such as quay.io/submariner/lighthouse-agent:devel, optionally with a digest.
+var imageReference = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-]+[a-z0-9]+)*)*` +
+	`(:[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?(@sha256:[0-9a-f]{64})?$`)
+
+// checkCIDRs verifies that the given service, cluster and global CIDRs are valid, and that they don't overlap each other,
+// which would break routing. Empty CIDRs aren't known yet (they may be discovered later) and are skipped.
+func checkCIDRs(serviceCIDR, clusterCIDR, globalCIDR string) error {
+	type namedPrefix struct {
+		name   string
+		prefix netip.Prefix
+	}
+
+	prefixes := []namedPrefix{}
+
+	for _, cidr := range []struct{ name, value string }{{"service", serviceCIDR}, {"cluster", clusterCIDR}, {"global", globalCIDR}} {
+		if cidr.value == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr.value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s CIDR %q", cidr.name, cidr.value)
+		}
+
+		prefixes = append(prefixes, namedPrefix{name: cidr.name, prefix: prefix})
+	}
+
+	overlaps := []string{}
+
+	for i := range prefixes {
+		for j := i + 1; j < len(prefixes); j++ {
+			if prefixes[i].prefix.Overlaps(prefixes[j].prefix) {
+				overlaps = append(overlaps, fmt.Sprintf("the %s CIDR %s overlaps the %s CIDR %s", prefixes[i].name,
+					prefixes[i].prefix, prefixes[j].name, prefixes[j].prefix))
+			}
+		}
+	}
+
+	if len(overlaps) > 0 {
+		return errors.New(strings.Join(overlaps, "; "))
+	}
+
+	return nil
+}
+
+// checkHealthCheckInterval verifies that the health check interval is within bounds when health checking is enabled; an
+// interval of 0 would have the gateways check continuously.
+func checkHealthCheckInterval(options *SubmarinerOptions) error {
+	if !options.HealthCheckEnabled {
+		return nil
+	}
+
+	if options.HealthCheckInterval < 1 || options.HealthCheckInterval > maxHealthCheckInterval {
+		return fmt.Errorf("HealthCheckInterval must be between 1 and %d seconds when the health check is enabled, got %d",
+			maxHealthCheckInterval, options.HealthCheckInterval)
+	}
+
+	return nil
+}
+
+// checkHealthCheckDetectionTime verifies that, with the given health check settings, an unhealthy connection is detected
+// within maxHealthCheckDetectionTime; beyond that, health checking is effectively useless.
+func checkHealthCheckDetectionTime(options *SubmarinerOptions) error {
+	if !options.HealthCheckEnabled {
+		return nil
+	}
+
+	detectionTime := healthCheckDetectionTime(options.HealthCheckInterval, options.HealthCheckMaxPacketLossCount)
+	if detectionTime > maxHealthCheckDetectionTime {
+		return fmt.Errorf("with a %ds interval and a maximum packet loss count of %d, unhealthy connections are only "+
+			"detected after %v, which exceeds %v", options.HealthCheckInterval, options.HealthCheckMaxPacketLossCount, detectionTime,
+			maxHealthCheckDetectionTime)
+	}
+
+	return nil
+}
+
+func healthCheckDetectionTime(intervalSeconds, maxPacketLossCount uint64) time.Duration {
+	maxSeconds := uint64(math.MaxInt64 / int64(time.Second))
+
+	if intervalSeconds != 0 && maxPacketLossCount > maxSeconds/intervalSeconds {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(intervalSeconds*maxPacketLossCount) * time.Second
+}
+
+// checkCustomDomainsRoot verifies that all the given custom domains are subdomains of the given root domain.
+func checkCustomDomainsRoot(domains []string, root string) error {
+	suffix := "." + normalizeDomain(root)
+	outside := []string{}
+
+	for _, domain := range domains {
+		if !strings.HasSuffix(normalizeDomain(domain), suffix) {
+			outside = append(outside, domain)
+		}
+	}
+
+	if len(outside) > 0 {
+		return fmt.Errorf("the custom domain(s) %s aren't subdomains of %s", strings.Join(outside, ", "), root)
+	}
+
+	return nil
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
+
+// checkImageReferences verifies that all the given image overrides are valid image references.
+func checkImageReferences(overrides map[string]string) error {
+	invalid := []string{}
+
+	for component, imageURL := range overrides {
+		if !imageReference.MatchString(imageURL) {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", component, imageURL))
+		}
+	}
+
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("the following image overrides aren't valid image references: %s", strings.Join(invalid, ", "))
+	}
+
+	return nil
+}
+
+// withLighthouseImageOverride returns a copy of the given image overrides, with the Lighthouse agent image overridden.
+func withLighthouseImageOverride(overrides map[string]string, lighthouseImage string, serviceDiscoveryEnabled bool,
+) (map[string]string, error) {
+	if !serviceDiscoveryEnabled {
+		return nil, errors.New("a Lighthouse image override was specified but service discovery isn't enabled on the broker")
+	}
+
+	if !imageReference.MatchString(lighthouseImage) {
+		return nil, fmt.Errorf("the Lighthouse image override %q isn't a valid image reference", lighthouseImage)
+	}
+
+	if existing, ok := overrides[names.ServiceDiscoveryComponent]; ok && existing != lighthouseImage {
+		return nil, fmt.Errorf("the Lighthouse image override %q conflicts with the %s image override %q", lighthouseImage,
+			names.ServiceDiscoveryComponent, existing)
+	}
+
+	merged := make(map[string]string, len(overrides)+1)
+	for component, imageURL := range overrides {
+		merged[component] = imageURL
+	}
+
+	merged[names.ServiceDiscoveryComponent] = lighthouseImage
+
+	return merged, nil
+}
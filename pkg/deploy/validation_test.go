@@ -0,0 +1,247 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	"github.com/submariner-io/subctl/pkg/image"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Submariner spec validation", func() {
+	var (
+		options      *deploy.SubmarinerOptions
+		netconfig    globalnet.Config
+		brokerInfo   *broker.Info
+		brokerSecret *v1.Secret
+	)
+
+	BeforeEach(func() {
+		options = newTestSubmarinerOptions()
+		netconfig = globalnet.Config{}
+		brokerInfo, brokerSecret = newTestBrokerInfo()
+	})
+
+	render := func() error {
+		_, err := deploy.RenderSubmariner(options, brokerInfo, brokerSecret, netconfig, image.NewRepositoryInfo("", "", nil))
+		return err
+	}
+
+	Context("health check settings", func() {
+		BeforeEach(func() {
+			options.HealthCheckEnabled = true
+			options.HealthCheckInterval = 60
+			options.HealthCheckMaxPacketLossCount = 10
+		})
+
+		When("the interval is 0", func() {
+			It("should return an error naming the field", func() {
+				options.HealthCheckInterval = 0
+				Expect(render()).To(MatchError(ContainSubstring("HealthCheckInterval")))
+			})
+		})
+
+		When("the interval is too long", func() {
+			It("should return an error", func() {
+				options.HealthCheckInterval = 301
+				options.HealthCheckMaxPacketLossCount = 1
+				Expect(render()).To(MatchError(ContainSubstring("HealthCheckInterval")))
+			})
+		})
+
+		When("the detection time is too long in strict mode", func() {
+			It("should return an error including the detection time", func() {
+				options.StrictHealthCheck = true
+				Expect(render()).To(MatchError(ContainSubstring("10m0s")))
+			})
+		})
+
+		When("the detection time is too long in non-strict mode", func() {
+			It("should succeed", func() {
+				Expect(render()).To(Succeed())
+			})
+		})
+
+		When("the health check is disabled", func() {
+			It("should succeed", func() {
+				options.StrictHealthCheck = true
+				options.HealthCheckEnabled = false
+				Expect(render()).To(Succeed())
+			})
+		})
+	})
+
+	Context("local CIDRs", func() {
+		When("the service and cluster CIDRs overlap", func() {
+			It("should return an error listing both", func() {
+				options.ServiceCIDR = "10.244.16.0/20"
+				err := render()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(options.ServiceCIDR))
+				Expect(err.Error()).To(ContainSubstring(options.ClusterCIDR))
+			})
+		})
+
+		When("a CIDR is invalid", func() {
+			It("should return an error", func() {
+				options.ClusterCIDR = "10.244.0.0/33"
+				Expect(render()).To(MatchError(ContainSubstring("invalid cluster CIDR")))
+			})
+		})
+	})
+
+	Context("global CIDR", func() {
+		When("it doesn't overlap the local CIDRs", func() {
+			It("should succeed", func() {
+				netconfig.GlobalCIDR = "242.0.0.0/16"
+				Expect(render()).To(Succeed())
+			})
+		})
+
+		When("it overlaps a local CIDR", func() {
+			It("should return an error listing the overlapping CIDR", func() {
+				netconfig.GlobalCIDR = "10.244.128.0/24"
+				err := render()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(options.ClusterCIDR))
+				Expect(err.Error()).ToNot(ContainSubstring(options.ServiceCIDR))
+			})
+		})
+	})
+
+	Context("Lighthouse image override", func() {
+		const lighthouseImage = "quay.io/example/lighthouse-agent:fix"
+
+		BeforeEach(func() {
+			options.LighthouseImageOverride = lighthouseImage
+		})
+
+		When("service discovery is enabled", func() {
+			It("should override the Lighthouse agent image only", func() {
+				output, err := deploy.RenderSubmariner(options, brokerInfo, brokerSecret, netconfig, image.NewRepositoryInfo("", "", nil))
+				Expect(err).To(Succeed())
+				Expect(string(output)).To(ContainSubstring(names.ServiceDiscoveryComponent + ": " + lighthouseImage))
+				Expect(string(output)).ToNot(ContainSubstring(names.LighthouseCoreDNSComponent))
+			})
+		})
+
+		When("service discovery isn't enabled", func() {
+			It("should return an error", func() {
+				brokerInfo.Components = []string{"connectivity"}
+				Expect(render()).ToNot(Succeed())
+			})
+		})
+
+		When("the image reference is invalid", func() {
+			It("should return an error", func() {
+				options.LighthouseImageOverride = "quay.io/example/lighthouse agent"
+				Expect(render()).ToNot(Succeed())
+			})
+		})
+	})
+
+	Context("custom domains root", func() {
+		BeforeEach(func() {
+			options.CustomDomainsRoot = "svc.mesh.example.com"
+		})
+
+		When("all the custom domains are under the root", func() {
+			It("should succeed", func() {
+				options.CustomDomains = []string{"east.svc.mesh.example.com", "West.Svc.Mesh.Example.Com."}
+				Expect(render()).To(Succeed())
+			})
+		})
+
+		When("some custom domains aren't under the root", func() {
+			It("should return an error listing them", func() {
+				options.CustomDomains = []string{"east.svc.mesh.example.com", "mesh.example.com", "evilsvc.mesh.example.com"}
+				err := render()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("mesh.example.com, evilsvc.mesh.example.com"))
+				Expect(err.Error()).ToNot(ContainSubstring("east."))
+			})
+		})
+	})
+
+	Context("raw image overrides", func() {
+		When("they are valid image references", func() {
+			It("should replace the repository's overrides verbatim", func() {
+				options.RawImageOverrides = map[string]string{names.GatewayComponent: "localhost:5000/gw@sha256:" + strings.Repeat("a", 64)}
+				output, err := deploy.RenderSubmariner(options, brokerInfo, brokerSecret, netconfig,
+					image.NewRepositoryInfo("", "", map[string]string{names.RouteAgentComponent: "quay.io/example/route-agent:dev"}))
+				Expect(err).To(Succeed())
+				Expect(string(output)).To(ContainSubstring(options.RawImageOverrides[names.GatewayComponent]))
+				Expect(string(output)).ToNot(ContainSubstring(names.RouteAgentComponent))
+			})
+		})
+
+		When("one isn't a valid image reference", func() {
+			It("should return an error", func() {
+				options.RawImageOverrides = map[string]string{names.GatewayComponent: "Quay.io/Example/GW:"}
+				Expect(render()).To(MatchError(ContainSubstring(names.GatewayComponent)))
+			})
+		})
+	})
+
+	Context("PSK external secret", func() {
+		BeforeEach(func() {
+			options.PSKExternalSecretKey = "submariner/psk"
+		})
+
+		When("the secret store reference is valid", func() {
+			It("should reference the secret without embedding the PSK", func() {
+				options.PSKExternalSecretStore = "ClusterSecretStore/vault"
+				output, err := deploy.RenderSubmariner(options, brokerInfo, brokerSecret, netconfig, image.NewRepositoryInfo("", "", nil))
+				Expect(err).To(Succeed())
+				Expect(string(output)).To(ContainSubstring("ceIPSecPSKSecret: submariner-ipsec-psk"))
+				Expect(string(output)).ToNot(ContainSubstring("ceIPSecPSK:"))
+			})
+		})
+
+		When("the secret store kind is invalid", func() {
+			It("should return an error", func() {
+				options.PSKExternalSecretStore = "Vault/vault"
+				Expect(render()).To(MatchError(ContainSubstring("Vault")))
+			})
+		})
+
+		When("the key is missing", func() {
+			It("should return an error", func() {
+				options.PSKExternalSecretStore = "vault"
+				options.PSKExternalSecretKey = ""
+				Expect(render()).ToNot(Succeed())
+			})
+		})
+
+		When("the broker information doesn't contain a PSK", func() {
+			It("should return an error", func() {
+				options.PSKExternalSecretStore = "ClusterSecretStore/vault"
+				brokerInfo.IPSecPSK = nil
+				Expect(render()).To(MatchError(ContainSubstring("doesn't contain an IPsec PSK")))
+			})
+		})
+	})
+})
@@ -0,0 +1,41 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/deploy"
+)
+
+var _ = Describe("VersionsCompatible", func() {
+	DescribeTable("comparing broker and cluster versions",
+		func(brokerVersion, clusterVersion string, expectedCompatible, expectedSkewed bool) {
+			compatible, skewed := deploy.VersionsCompatible(brokerVersion, clusterVersion)
+			Expect(compatible).To(Equal(expectedCompatible))
+			Expect(skewed).To(Equal(expectedSkewed))
+		},
+		Entry("same version", "0.15.0", "0.15.0", true, false),
+		Entry("different patch versions", "0.15.0", "v0.15.2", true, false),
+		Entry("one minor version apart", "0.14.1", "0.15.0", true, true),
+		Entry("two minor versions apart", "0.15.0", "0.13.0", false, false),
+		Entry("different major versions", "1.0.0", "0.15.0", false, false),
+		Entry("a development version", "devel", "0.12.0", true, false),
+	)
+})
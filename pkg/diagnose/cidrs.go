@@ -0,0 +1,102 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"context"
+	"errors"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/cidr"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type clusterCIDR struct {
+	clusterID string
+	kind      string
+	cidr      string
+}
+
+// ClusterCIDRs checks that the CIDRs of all the clusters joined to the broker don't overlap. With Globalnet, only the
+// global CIDRs need to be distinct; otherwise, the cluster (Pod) and Service CIDRs of all the clusters must be distinct.
+func ClusterCIDRs(clusterInfo *cluster.Info, _ string, status reporter.Interface) error {
+	mustHaveSubmariner(clusterInfo)
+
+	status.Start("Checking that the CIDRs of the joined clusters don't overlap")
+	defer status.End()
+
+	clusters := &submarinerv1.ClusterList{}
+
+	err := clusterInfo.ClientProducer.ForGeneral().List(context.TODO(), clusters,
+		controllerClient.InNamespace(clusterInfo.Submariner.Namespace))
+	if err != nil {
+		return status.Error(err, "Error listing the Submariner clusters")
+	}
+
+	globalnet := clusterInfo.Submariner.Spec.GlobalCIDR != ""
+	cidrs := []clusterCIDR{}
+
+	for i := range clusters.Items {
+		spec := &clusters.Items[i].Spec
+
+		if globalnet {
+			cidrs = appendClusterCIDRs(cidrs, spec.ClusterID, "global CIDR", spec.GlobalCIDR)
+		} else {
+			cidrs = appendClusterCIDRs(cidrs, spec.ClusterID, "cluster CIDR", spec.ClusterCIDR)
+			cidrs = appendClusterCIDRs(cidrs, spec.ClusterID, "service CIDR", spec.ServiceCIDR)
+		}
+	}
+
+	tracker := reporter.NewTracker(status)
+
+	for i := range cidrs {
+		for j := i + 1; j < len(cidrs); j++ {
+			if cidrs[i].clusterID == cidrs[j].clusterID {
+				continue
+			}
+
+			overlap, err := cidr.IsOverlapping([]string{cidrs[i].cidr}, cidrs[j].cidr)
+			if err != nil {
+				tracker.Failure("Error comparing the %s of cluster %q with the %s of cluster %q: %v", cidrs[i].kind,
+					cidrs[i].clusterID, cidrs[j].kind, cidrs[j].clusterID, err)
+			} else if overlap {
+				tracker.Failure("The %s %q of cluster %q overlaps with the %s %q of cluster %q", cidrs[i].kind, cidrs[i].cidr,
+					cidrs[i].clusterID, cidrs[j].kind, cidrs[j].cidr, cidrs[j].clusterID)
+			}
+		}
+	}
+
+	if tracker.HasFailures() {
+		return errors.New("failures while diagnosing the cluster CIDRs")
+	}
+
+	status.Success("The CIDRs of the %d joined clusters don't overlap", len(clusters.Items))
+
+	return nil
+}
+
+func appendClusterCIDRs(cidrs []clusterCIDR, clusterID, kind string, values []string) []clusterCIDR {
+	for _, value := range values {
+		cidrs = append(cidrs, clusterCIDR{clusterID: clusterID, kind: kind, cidr: value})
+	}
+
+	return cidrs
+}
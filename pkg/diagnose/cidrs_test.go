@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/diagnose"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ClusterCIDRs", func() {
+	newCluster := func(clusterID, clusterCIDR, serviceCIDR, globalCIDR string) controllerClient.Object {
+		cluster := &submarinerv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterID, Namespace: constants.OperatorNamespace},
+			Spec: submarinerv1.ClusterSpec{
+				ClusterID:   clusterID,
+				ClusterCIDR: []string{clusterCIDR},
+				ServiceCIDR: []string{serviceCIDR},
+			},
+		}
+
+		if globalCIDR != "" {
+			cluster.Spec.GlobalCIDR = []string{globalCIDR}
+		}
+
+		return cluster
+	}
+
+	DescribeTable("checking the CIDRs of the joined clusters",
+		func(globalCIDR string, clusters []controllerClient.Object, expectedStatus string) {
+			recorder, status := newRecorder()
+
+			err := diagnose.ClusterCIDRs(newClusterInfo("east", &operatorv1alpha1.SubmarinerSpec{GlobalCIDR: globalCIDR}, nil,
+				clusters...), constants.OperatorNamespace, status)
+			if expectedStatus == cli.CheckFailed {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).To(Succeed())
+			}
+
+			Expect(statusesOf(recorder)).To(Equal([]string{expectedStatus}))
+		},
+		Entry("distinct CIDRs", "", []controllerClient.Object{
+			newCluster("east", "10.0.0.0/16", "100.0.0.0/16", ""),
+			newCluster("west", "10.1.0.0/16", "100.1.0.0/16", ""),
+		}, cli.CheckPassed),
+		Entry("overlapping cluster CIDRs", "", []controllerClient.Object{
+			newCluster("east", "10.0.0.0/16", "100.0.0.0/16", ""),
+			newCluster("west", "10.0.128.0/17", "100.1.0.0/16", ""),
+		}, cli.CheckFailed),
+		Entry("a service CIDR overlapping another cluster's cluster CIDR", "", []controllerClient.Object{
+			newCluster("east", "10.0.0.0/16", "100.0.0.0/16", ""),
+			newCluster("west", "100.0.0.0/24", "100.1.0.0/16", ""),
+		}, cli.CheckFailed),
+		Entry("overlapping CIDRs within the same cluster", "", []controllerClient.Object{
+			newCluster("east", "10.0.0.0/16", "10.0.0.0/16", ""),
+		}, cli.CheckPassed),
+		Entry("overlapping cluster CIDRs with distinct global CIDRs", "242.0.0.0/16", []controllerClient.Object{
+			newCluster("east", "10.0.0.0/16", "100.0.0.0/16", "242.0.0.0/16"),
+			newCluster("west", "10.0.0.0/16", "100.0.0.0/16", "242.1.0.0/16"),
+		}, cli.CheckPassed),
+		Entry("overlapping global CIDRs", "242.0.0.0/16", []controllerClient.Object{
+			newCluster("east", "10.0.0.0/16", "100.0.0.0/16", "242.0.0.0/16"),
+			newCluster("west", "10.1.0.0/16", "100.1.0.0/16", "242.0.0.0/24"),
+		}, cli.CheckFailed),
+	)
+})
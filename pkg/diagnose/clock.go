@@ -0,0 +1,95 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/pods"
+	"github.com/submariner-io/subctl/pkg/cluster"
+)
+
+const (
+	maxClockSkew       = 5 * time.Second
+	clockQueryCommand  = "date -u +%s"
+	clockSkewRationale = "IPsec and the gateway health checks are sensitive to clock skew, please check the node's NTP configuration"
+)
+
+// ClockSkew compares the time on the gateway node with that of the local machine. The pod's startup time is part of the
+// measurement, so the reported offset is only the part which falls outside of the time spent running the pod.
+func ClockSkew(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	status.Start("Checking the clock skew between the gateway node and the local machine")
+	defer status.End()
+
+	repositoryInfo, err := clusterInfo.GetImageRepositoryInfo(firewallImageOverrides...)
+	if err != nil {
+		return status.Error(err, "Error determining repository information")
+	}
+
+	before := time.Now().Truncate(time.Second)
+
+	podOutput, err := pods.ScheduleAndAwaitCompletion(&pods.Config{
+		Name:                "query-clock",
+		ClientSet:           clusterInfo.ClientProducer.ForKubernetes(),
+		Scheduling:          pods.Scheduling{ScheduleOn: pods.GatewayNode, Networking: pods.PodNetworking},
+		Namespace:           namespace,
+		Command:             clockQueryCommand,
+		ImageRepositoryInfo: *repositoryInfo,
+	})
+	if err != nil {
+		return status.Error(err, "Error spawning the clock query pod")
+	}
+
+	after := time.Now()
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(podOutput), 10, 64)
+	if err != nil {
+		status.Warning("Unable to parse the gateway node time %q, skipping the clock skew check", strings.TrimSpace(podOutput))
+		return nil
+	}
+
+	offset := clockOffset(time.Unix(seconds, 0), before, after)
+
+	if offset > maxClockSkew || offset < -maxClockSkew {
+		status.Failure("The clock of the gateway node is %v off from the local machine, beyond the %v threshold. %s",
+			offset, maxClockSkew, clockSkewRationale)
+
+		return nil
+	}
+
+	status.Success("The clock of the gateway node is %v off from the local machine (measured over %v)", offset,
+		after.Sub(before).Round(time.Second))
+
+	return nil
+}
+
+// clockOffset returns how far the remote time falls outside of the local [before, after] interval in which it was measured.
+func clockOffset(remote, before, after time.Time) time.Duration {
+	switch {
+	case remote.Before(before):
+		return remote.Sub(before)
+	case remote.After(after):
+		return remote.Sub(after).Round(time.Second)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,60 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose_test
+
+import (
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/diagnose"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("ClockSkew", func() {
+	DescribeTable("comparing the gateway node time",
+		func(gatewayTime func() string, expectedStatus string) {
+			recorder, status := newRecorder()
+
+			Expect(diagnose.ClockSkew(newClusterInfo("east", &operatorv1alpha1.SubmarinerSpec{}, func(_ *corev1.Pod) string {
+				return gatewayTime()
+			}), constants.OperatorNamespace, status)).To(Succeed())
+			Expect(statusesOf(recorder)).To(Equal([]string{expectedStatus}))
+		},
+		Entry("a gateway node in sync", func() string {
+			return strconv.FormatInt(time.Now().Unix(), 10) + "\n"
+		}, cli.CheckPassed),
+		Entry("a gateway node within the threshold", func() string {
+			return strconv.FormatInt(time.Now().Add(3*time.Second).Unix(), 10)
+		}, cli.CheckPassed),
+		Entry("a gateway node ahead", func() string {
+			return strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+		}, cli.CheckFailed),
+		Entry("a gateway node behind", func() string {
+			return strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+		}, cli.CheckFailed),
+		Entry("an unparsable gateway node time", func() string {
+			return "date: invalid option"
+		}, cli.CheckWarning),
+	)
+})
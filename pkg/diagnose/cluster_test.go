@@ -0,0 +1,144 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose_test
+
+import (
+	"strconv"
+
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newClusterInfo returns the information for a cluster with Submariner deployed using the given spec. Its Kubernetes
+// client holds the operator namespace and the given objects, and the pods created through it complete immediately, with
+// the output returned by podOutput.
+func newClusterInfo(name string, spec *operatorv1alpha1.SubmarinerSpec, podOutput func(pod *corev1.Pod) string,
+	objects ...controllerClient.Object,
+) *cluster.Info {
+	scheme := runtime.NewScheme()
+	Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(submarinerv1.AddToScheme(scheme)).To(Succeed())
+
+	kubeObjects := []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: constants.OperatorNamespace}}}
+	generalObjects := []controllerClient.Object{}
+
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *corev1.Pod, *corev1.ConfigMap:
+			kubeObjects = append(kubeObjects, obj)
+		default:
+			generalObjects = append(generalObjects, obj)
+		}
+	}
+
+	kubeClient := fake.NewSimpleClientset(kubeObjects...)
+	completePods(kubeClient, podOutput)
+
+	return &cluster.Info{
+		Name: name,
+		ClientProducer: &client.DefaultProducer{
+			KubeClient:    kubeClient,
+			GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(generalObjects...).Build(),
+		},
+		Submariner: &operatorv1alpha1.Submariner{
+			ObjectMeta: metav1.ObjectMeta{Name: "submariner", Namespace: constants.OperatorNamespace},
+			Spec:       *spec,
+		},
+	}
+}
+
+// completePods makes the pods created through the given client complete as soon as they are retrieved, with the output
+// returned by podOutput at that time.
+func completePods(kubeClient *fake.Clientset, podOutput func(pod *corev1.Pod) string) {
+	created := 0
+
+	kubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		if pod.Name == "" {
+			created++
+			pod.Name = pod.GenerateName + strconv.Itoa(created)
+		}
+
+		return false, nil, nil
+	})
+
+	kubeClient.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := kubeClient.Tracker().Get(corev1.SchemeGroupVersion.WithResource("pods"), action.GetNamespace(),
+			action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+
+		pod := obj.(*corev1.Pod).DeepCopy()
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: podOutput(pod)}},
+			}},
+		}
+
+		return true, pod, nil
+	})
+}
+
+// podCommand returns the command run by the given pod.
+func podCommand(pod *corev1.Pod) string {
+	for _, env := range pod.Spec.Containers[0].Env {
+		if env.Name == "COMMAND" {
+			return env.Value
+		}
+	}
+
+	return ""
+}
+
+func newGateway(name string, status *submarinerv1.GatewayStatus) *submarinerv1.Gateway {
+	return &submarinerv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: constants.OperatorNamespace},
+		Status:     *status,
+	}
+}
+
+func newRecorder() (*cli.Recorder, reporter.Interface) {
+	recorder := cli.NewRecorder(reporter.Silent())
+	return recorder, recorder.Reporter()
+}
+
+// statusesOf returns the status of each of the recorded results.
+func statusesOf(recorder *cli.Recorder) []string {
+	statuses := []string{}
+	for _, result := range recorder.Results() {
+		statuses = append(statuses, result.Status)
+	}
+
+	return statuses
+}
@@ -0,0 +1,169 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/pods"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// NATTPortAcrossClusters checks that the encapsulation (NAT-T) port of each cluster's active gateway is reachable from the
+// active gateway of the other cluster, on both the public and private IPs of its endpoint. The port is the one advertised
+// by the endpoint, which follows the CeIPSecNATTPort of the deployed spec. Unreachable IPs are reported as failures when
+// the connection between the clusters uses them, and as warnings otherwise.
+func NATTPortAcrossClusters(localClusterInfo, remoteClusterInfo *cluster.Info, namespace string, options FirewallOptions,
+	status reporter.Interface,
+) error {
+	mustHaveSubmariner(localClusterInfo)
+	mustHaveSubmariner(remoteClusterInfo)
+
+	return k8serrors.NewAggregate([]error{
+		verifyNATTPort(localClusterInfo, remoteClusterInfo, namespace, options, status),
+		verifyNATTPort(remoteClusterInfo, localClusterInfo, namespace, options, status),
+	})
+}
+
+func verifyNATTPort(targetClusterInfo, sourceClusterInfo *cluster.Info, namespace string, options FirewallOptions,
+	status reporter.Interface,
+) error {
+	status.Start("Checking the NAT-T port of the gateway of cluster %q from the gateway of cluster %q",
+		targetClusterInfo.Name, sourceClusterInfo.Name)
+	defer status.End()
+
+	endpoint, err := targetClusterInfo.GetLocalEndpoint()
+	if err != nil {
+		return status.Error(err, "Unable to obtain the local endpoint of cluster %q", targetClusterInfo.Name)
+	}
+
+	destPort, err := getTargetPort(targetClusterInfo.Submariner, endpoint, TunnelPort)
+	if err != nil {
+		return status.Error(err, "Could not determine the NAT-T port")
+	}
+
+	portFilter, err := getPortFilter(destPort, targetClusterInfo, endpoint, TunnelPort, status)
+	if err != nil {
+		return err
+	}
+
+	targetNodeName, err := getActiveGatewayNodeName(targetClusterInfo, status)
+	if err != nil {
+		return err
+	}
+
+	sourceNodeName, err := getActiveGatewayNodeName(sourceClusterInfo, status)
+	if err != nil {
+		return err
+	}
+
+	connectionIP := ""
+	if gateway, gwErr := activeGateway(sourceClusterInfo); gwErr == nil {
+		for i := range gateway.Status.Connections {
+			if connection := &gateway.Status.Connections[i]; connection.Endpoint.ClusterID == endpoint.Spec.ClusterID {
+				connectionIP = connection.UsingIP
+			}
+		}
+	}
+
+	probeErrors := []error{}
+
+	for _, target := range []struct{ kind, ip string }{{"public", endpoint.Spec.PublicIP}, {"private", endpoint.Spec.PrivateIP}} {
+		if target.ip == "" {
+			continue
+		}
+
+		reachable, err := probeUDPPort(targetClusterInfo, sourceClusterInfo, namespace, options, targetNodeName, sourceNodeName,
+			portFilter, target.ip, destPort)
+		if err != nil {
+			probeErrors = append(probeErrors, status.Error(err, "Error probing UDP/%d on the %s IP %s of cluster %q", destPort,
+				target.kind, target.ip, targetClusterInfo.Name))
+
+			continue
+		}
+
+		switch {
+		case reachable:
+			status.Success("UDP/%d on the %s IP %s of cluster %q is reachable from the gateway of cluster %q", destPort,
+				target.kind, target.ip, targetClusterInfo.Name, sourceClusterInfo.Name)
+		case target.ip == connectionIP:
+			status.Failure("UDP/%d on the %s IP %s of cluster %q, used by the connection from cluster %q, isn't reachable. "+
+				"Please check that your firewall configuration allows UDP/%d traffic on the %q node", destPort, target.kind,
+				target.ip, targetClusterInfo.Name, sourceClusterInfo.Name, destPort, targetNodeName)
+			probeErrors = append(probeErrors, fmt.Errorf("UDP/%d on %s isn't reachable from cluster %q", destPort, target.ip,
+				sourceClusterInfo.Name))
+		default:
+			status.Warning("UDP/%d on the %s IP %s of cluster %q isn't reachable from the gateway of cluster %q", destPort,
+				target.kind, target.ip, targetClusterInfo.Name, sourceClusterInfo.Name)
+		}
+	}
+
+	return k8serrors.NewAggregate(probeErrors)
+}
+
+// probeUDPPort sends UDP packets from the source gateway node to the given IP and port, and reports whether a sniffer on
+// the target gateway node saw them.
+func probeUDPPort(targetClusterInfo, sourceClusterInfo *cluster.Info, namespace string, options FirewallOptions,
+	targetNodeName, sourceNodeName, portFilter, targetIP string, targetPort int32,
+) (bool, error) {
+	repositoryInfo, err := targetClusterInfo.GetImageRepositoryInfo(firewallImageOverrides...)
+	if err != nil {
+		return false, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	clientMessage := string(uuid.NewUUID())[0:8]
+	podCommand := fmt.Sprintf(
+		"(tcpdump --immediate-mode -ln -Q in -A -s 100 -i any udp and %s & pid=\"$!\"; (sleep %d; kill \"$pid\") &) | sed '/%s/q'",
+		portFilter, options.ValidationTimeout, clientMessage)
+
+	sPod, err := spawnSnifferPodOnNode(targetClusterInfo.ClientProducer.ForKubernetes(), targetNodeName, namespace, podCommand,
+		repositoryInfo)
+	if err != nil {
+		return false, err
+	}
+
+	defer sPod.Delete()
+
+	podCommand = fmt.Sprintf("for x in $(seq 1000); do echo %s; done | for i in $(seq 5);"+
+		" do timeout 2 nc -n -p %s -u %s %d; done", clientMessage, clientSourcePort, targetIP, targetPort)
+
+	cPod, err := spawnPod(sourceClusterInfo.ClientProducer.ForKubernetes(), pods.Scheduling{
+		ScheduleOn: pods.CustomNode, NodeName: sourceNodeName,
+		Networking: pods.HostNetworking,
+	}, "validate-natt-client", namespace, podCommand, repositoryInfo)
+	if err != nil {
+		return false, err
+	}
+
+	defer cPod.Delete()
+
+	if err = cPod.AwaitCompletion(); err != nil {
+		return false, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	if err = sPod.AwaitCompletion(); err != nil {
+		return false, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	return strings.Contains(sPod.PodOutput, clientMessage), nil
+}
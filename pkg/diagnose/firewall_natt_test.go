@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/diagnose"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NATTPortAcrossClusters", func() {
+	clientCommand := regexp.MustCompile(`echo (\S+); done .* -u (\S+) \d+`)
+
+	var (
+		reachable map[string]bool
+		message   string
+		targetIP  string
+	)
+
+	// The client pod sends a message to the target IP, which the sniffer pod sees if the IP is reachable
+	podOutput := func(pod *corev1.Pod) string {
+		if matches := clientCommand.FindStringSubmatch(podCommand(pod)); matches != nil {
+			message, targetIP = matches[1], matches[2]
+			return ""
+		}
+
+		if reachable[targetIP] {
+			return "IP 10.0.0.1.4500 > " + targetIP + ".4500: UDP, length 9\n" + message
+		}
+
+		return ""
+	}
+
+	newCluster := func(clusterID, publicIP, privateIP, remoteClusterID, remotePrivateIP string) *cluster.Info {
+		return newClusterInfo(clusterID, &operatorv1alpha1.SubmarinerSpec{ClusterID: clusterID, CeIPSecNATTPort: 4500}, podOutput,
+			&submarinerv1.Endpoint{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterID, Namespace: constants.OperatorNamespace},
+				Spec: submarinerv1.EndpointSpec{
+					ClusterID: clusterID,
+					Backend:   "libreswan",
+					PublicIP:  publicIP,
+					PrivateIP: privateIP,
+				},
+			},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      "gateway",
+				Namespace: constants.OperatorNamespace,
+				Labels: map[string]string{
+					"app":                          names.GatewayComponent,
+					"gateway.submariner.io/status": "active",
+					"gateway.submariner.io/node":   "node-" + clusterID,
+				},
+			}},
+			newGateway("node-"+clusterID, &submarinerv1.GatewayStatus{
+				HAStatus: submarinerv1.HAStatusActive,
+				Connections: []submarinerv1.Connection{{
+					Endpoint: submarinerv1.EndpointSpec{ClusterID: remoteClusterID},
+					UsingIP:  remotePrivateIP,
+				}},
+			}))
+	}
+
+	DescribeTable("checking the NAT-T ports",
+		func(reachableIPs []string, expectedStatus string) {
+			reachable = map[string]bool{}
+			for _, ip := range reachableIPs {
+				reachable[ip] = true
+			}
+
+			recorder, status := newRecorder()
+
+			err := diagnose.NATTPortAcrossClusters(newCluster("east", "1.1.1.1", "10.0.0.1", "west", "10.1.0.1"),
+				newCluster("west", "2.2.2.2", "10.1.0.1", "east", "10.0.0.1"), constants.OperatorNamespace,
+				diagnose.FirewallOptions{ValidationTimeout: 1}, status)
+			if expectedStatus == cli.CheckFailed {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).To(Succeed())
+			}
+
+			Expect(statusesOf(recorder)).To(Equal([]string{expectedStatus, expectedStatus}))
+		},
+		Entry("all the IPs reachable", []string{"1.1.1.1", "10.0.0.1", "2.2.2.2", "10.1.0.1"}, cli.CheckPassed),
+		Entry("unreachable IPs used by the connections", []string{"1.1.1.1", "2.2.2.2"}, cli.CheckFailed),
+		Entry("unreachable IPs not used by the connections", []string{"10.0.0.1", "10.1.0.1"}, cli.CheckWarning),
+	)
+})
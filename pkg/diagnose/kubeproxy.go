@@ -19,55 +19,152 @@ limitations under the License.
 package diagnose
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
 	"github.com/spf13/pflag"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/pods"
 	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/deploy"
 )
 
 const (
 	kubeProxyIPVSIfaceCommand = "ip a s kube-ipvs0"
 	missingInterface          = "ip: can't find device"
 	notEnabled                = "Device \"kube-ipvs0\" does not exist"
+	kubeProxyIPVSMode         = "ipvs"
+	kubeProxyIPTablesMode     = "iptables"
+	kubeProxyRemediation      = "Switch kube-proxy to iptables mode by setting \"mode: iptables\" in the kube-proxy ConfigMap " +
+		"in the kube-system namespace and restarting the kube-proxy pods"
 )
 
 var kubeProxyImageOverrides = []string{}
 
+type kubeProxyDeployment struct {
+	mode        string
+	cableDriver string
+	globalnet   bool
+}
+
+type kubeProxySupport struct {
+	supported bool
+	// reason explains why the combination is problematic, it's a failure if it isn't supported and a warning otherwise
+	reason string
+}
+
+const (
+	ipvsReason = "cross-cluster service traffic is only routed after IPVS has translated the service IPs, which the " +
+		"Submariner routing rules haven't been validated with"
+	ipvsGlobalnetReason = "globalnet directs the traffic for exported services to kube-proxy's iptables service chains, " +
+		"which aren't programmed in IPVS mode"
+)
+
+// kubeProxyCompatibility records how Submariner behaves with the kube-proxy modes, for each cable driver with and without
+// globalnet. Combinations which aren't listed haven't been validated.
+var kubeProxyCompatibility = map[kubeProxyDeployment]kubeProxySupport{
+	{kubeProxyIPTablesMode, "libreswan", false}: {supported: true},
+	{kubeProxyIPTablesMode, "libreswan", true}:  {supported: true},
+	{kubeProxyIPTablesMode, "vxlan", false}:     {supported: true},
+	{kubeProxyIPTablesMode, "vxlan", true}:      {supported: true},
+	{kubeProxyIPTablesMode, "wireguard", false}: {supported: true},
+	{kubeProxyIPTablesMode, "wireguard", true}:  {supported: true},
+	{kubeProxyIPVSMode, "libreswan", false}:     {supported: true, reason: ipvsReason},
+	{kubeProxyIPVSMode, "libreswan", true}:      {reason: ipvsGlobalnetReason},
+	{kubeProxyIPVSMode, "vxlan", false}:         {supported: true, reason: ipvsReason},
+	{kubeProxyIPVSMode, "vxlan", true}:          {reason: ipvsGlobalnetReason},
+	{kubeProxyIPVSMode, "wireguard", false}:     {supported: true, reason: ipvsReason},
+	{kubeProxyIPVSMode, "wireguard", true}:      {reason: ipvsGlobalnetReason},
+}
+
 func AddKubeProxyImageOverrideFlag(flags *pflag.FlagSet) {
-	flags.StringSliceVar(&firewallImageOverrides, "image-override", nil, "override component image")
+	flags.StringSliceVar(&kubeProxyImageOverrides, "image-override", nil, "override component image")
 }
 
+// KubeProxyMode checks the kube-proxy mode against the deployed cable driver and globalnet setting. The mode is read from
+// the kube-proxy configuration in kube-system; if that isn't present, the gateway node is probed for the IPVS interface.
+// The check is skipped when kube-proxy can't be found, e.g. on clusters using a kube-proxy replacement.
 func KubeProxyMode(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
 	status.Start("Checking Submariner support for the kube-proxy mode")
 	defer status.End()
 
-	scheduling := pods.Scheduling{ScheduleOn: pods.GatewayNode, Networking: pods.HostNetworking}
+	mode, _, err := deploy.CheckKubeProxyMode(context.TODO(), clusterInfo.ClientProducer.ForKubernetes())
+	if err != nil {
+		return status.Error(err, "Error determining the kube-proxy mode")
+	}
+
+	if mode == "" {
+		mode, err = probeKubeProxyMode(clusterInfo, namespace)
+		if err != nil {
+			return status.Error(err, "Error spawning the network pod")
+		}
+	}
 
+	if mode == "" {
+		status.Warning("Unable to find kube-proxy on the cluster, skipping the check. The cluster may be using a kube-proxy replacement.")
+		return nil
+	}
+
+	deployment := kubeProxyDeployment{
+		mode:        mode,
+		cableDriver: clusterInfo.Submariner.Spec.CableDriver,
+		globalnet:   clusterInfo.Submariner.Spec.GlobalCIDR != "",
+	}
+
+	if deployment.cableDriver == "" {
+		deployment.cableDriver = "libreswan"
+	}
+
+	description := fmt.Sprintf("the %s cable driver", deployment.cableDriver)
+	if deployment.globalnet {
+		description += " and globalnet"
+	}
+
+	support, found := kubeProxyCompatibility[deployment]
+	if !found {
+		status.Warning("kube-proxy runs in %s mode, which Submariner hasn't been validated with using %s. %s", mode, description,
+			kubeProxyRemediation)
+		return nil
+	}
+
+	switch {
+	case !support.supported:
+		status.Failure("kube-proxy runs in %s mode, which Submariner does not support with %s: %s. %s", mode, description,
+			support.reason, kubeProxyRemediation)
+	case support.reason != "":
+		status.Warning("kube-proxy runs in %s mode, which is known to be problematic with %s: %s. %s", mode, description,
+			support.reason, kubeProxyRemediation)
+	default:
+		status.Success("kube-proxy runs in %s mode, which is supported with %s", mode, description)
+	}
+
+	return nil
+}
+
+// probeKubeProxyMode checks for the kube-proxy IPVS interface on the gateway node, returning the ipvs mode if it's present
+// and an empty mode otherwise.
+func probeKubeProxyMode(clusterInfo *cluster.Info, namespace string) (string, error) {
 	repositoryInfo, err := clusterInfo.GetImageRepositoryInfo(kubeProxyImageOverrides...)
 	if err != nil {
-		return status.Error(err, "Error determining repository information")
+		return "", err //nolint:wrapcheck // No need to wrap errors here.
 	}
 
 	podOutput, err := pods.ScheduleAndAwaitCompletion(&pods.Config{
 		Name:                "query-iface-list",
 		ClientSet:           clusterInfo.ClientProducer.ForKubernetes(),
-		Scheduling:          scheduling,
+		Scheduling:          pods.Scheduling{ScheduleOn: pods.GatewayNode, Networking: pods.HostNetworking},
 		Namespace:           namespace,
 		Command:             kubeProxyIPVSIfaceCommand,
 		ImageRepositoryInfo: *repositoryInfo,
 	})
 	if err != nil {
-		return status.Error(err, "Error spawning the network pod")
+		return "", err //nolint:wrapcheck // No need to wrap errors here.
 	}
 
-	if !(strings.Contains(podOutput, missingInterface) || strings.Contains(podOutput, notEnabled)) {
-		status.Failure("The cluster is deployed with kube-proxy ipvs mode which Submariner does not support")
-		return nil
+	if strings.Contains(podOutput, missingInterface) || strings.Contains(podOutput, notEnabled) {
+		return "", nil
 	}
 
-	status.Success("The kube-proxy mode is supported")
-
-	return nil
+	return kubeProxyIPVSMode, nil
 }
@@ -0,0 +1,75 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/diagnose"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("KubeProxyMode", func() {
+	newConfigMap := func(mode string) controllerClient.Object {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-proxy", Namespace: metav1.NamespaceSystem},
+			Data:       map[string]string{"config.conf": "mode: " + mode + "\n"},
+		}
+	}
+
+	DescribeTable("checking the kube-proxy mode",
+		func(spec *operatorv1alpha1.SubmarinerSpec, probeOutput string, objects []controllerClient.Object, expectedStatus string) {
+			recorder, status := newRecorder()
+
+			Expect(diagnose.KubeProxyMode(newClusterInfo("east", spec, func(_ *corev1.Pod) string {
+				return probeOutput
+			}, objects...), constants.OperatorNamespace, status)).To(Succeed())
+			Expect(statusesOf(recorder)).To(Equal([]string{expectedStatus}))
+		},
+		Entry("iptables mode with the default cable driver", &operatorv1alpha1.SubmarinerSpec{}, "",
+			[]controllerClient.Object{newConfigMap("iptables")}, cli.CheckPassed),
+		Entry("iptables mode with vxlan and globalnet", &operatorv1alpha1.SubmarinerSpec{CableDriver: "vxlan", GlobalCIDR: "242.0.0.0/16"},
+			"", []controllerClient.Object{newConfigMap("iptables")}, cli.CheckPassed),
+		Entry("iptables mode with an unknown cable driver", &operatorv1alpha1.SubmarinerSpec{CableDriver: "custom"}, "",
+			[]controllerClient.Object{newConfigMap("iptables")}, cli.CheckWarning),
+		Entry("ipvs mode with libreswan", &operatorv1alpha1.SubmarinerSpec{CableDriver: "libreswan"}, "",
+			[]controllerClient.Object{newConfigMap("ipvs")}, cli.CheckWarning),
+		Entry("ipvs mode with wireguard", &operatorv1alpha1.SubmarinerSpec{CableDriver: "wireguard"}, "",
+			[]controllerClient.Object{newConfigMap("ipvs")}, cli.CheckWarning),
+		Entry("ipvs mode with vxlan", &operatorv1alpha1.SubmarinerSpec{CableDriver: "vxlan"}, "",
+			[]controllerClient.Object{newConfigMap("ipvs")}, cli.CheckWarning),
+		Entry("ipvs mode with libreswan and globalnet", &operatorv1alpha1.SubmarinerSpec{GlobalCIDR: "242.0.0.0/16"}, "",
+			[]controllerClient.Object{newConfigMap("ipvs")}, cli.CheckFailed),
+		Entry("ipvs mode with wireguard and globalnet",
+			&operatorv1alpha1.SubmarinerSpec{CableDriver: "wireguard", GlobalCIDR: "242.0.0.0/16"}, "",
+			[]controllerClient.Object{newConfigMap("ipvs")}, cli.CheckFailed),
+		Entry("another mode", &operatorv1alpha1.SubmarinerSpec{CableDriver: "vxlan"}, "",
+			[]controllerClient.Object{newConfigMap("nftables")}, cli.CheckWarning),
+		Entry("no configuration and the IPVS interface on a globalnet gateway",
+			&operatorv1alpha1.SubmarinerSpec{GlobalCIDR: "242.0.0.0/16"}, "3: kube-ipvs0: <BROADCAST,NOARP> mtu 1500", nil,
+			cli.CheckFailed),
+		Entry("no configuration and no IPVS interface on the gateway", &operatorv1alpha1.SubmarinerSpec{},
+			"ip: can't find device 'kube-ipvs0'", nil, cli.CheckWarning),
+	)
+})
@@ -0,0 +1,215 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	lhconstants "github.com/submariner-io/lighthouse/pkg/constants"
+	"github.com/submariner-io/subctl/internal/component"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	discovery "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+)
+
+const (
+	GlobalnetSetting        = "globalnet"
+	ServiceDiscoverySetting = "service-discovery"
+)
+
+// Inconsistency describes a cluster whose setting doesn't match the rest of the mesh.
+type Inconsistency struct {
+	Expected  bool
+	Actual    bool
+	ClusterID string
+	Setting   string
+}
+
+// CheckMeshConsistency compares the globalnet and service discovery settings of all the clusters registered with the
+// broker. Each broker namespace is checked separately. The expected settings are the broker's: its globalnet
+// configuration, and whether its Broker resource enables service discovery. When the broker doesn't say, the setting used
+// by most clusters is expected. A cluster is considered to run service discovery if it synced ServiceImports or
+// EndpointSlices to the broker, so a cluster which doesn't export any service is reported as lacking service discovery.
+func CheckMeshConsistency(ctx context.Context, brokerClient controllerClient.Client) ([]Inconsistency, error) {
+	clusters := &submarinerv1.ClusterList{}
+
+	err := brokerClient.List(ctx, clusters, controllerClient.InNamespace(metav1.NamespaceAll))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the Clusters registered with the broker")
+	}
+
+	clustersByNamespace := map[string][]string{}
+	globalnetEnabled := map[string]bool{}
+
+	for i := range clusters.Items {
+		spec := &clusters.Items[i].Spec
+		clustersByNamespace[clusters.Items[i].Namespace] = append(clustersByNamespace[clusters.Items[i].Namespace], spec.ClusterID)
+		globalnetEnabled[spec.ClusterID] = len(spec.GlobalCIDR) > 0
+	}
+
+	namespaces := make([]string, 0, len(clustersByNamespace))
+	for namespace := range clustersByNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+
+	sort.Strings(namespaces)
+
+	inconsistencies := []Inconsistency{}
+
+	for _, namespace := range namespaces {
+		clusterIDs := clustersByNamespace[namespace]
+
+		expectedGlobalnet, err := brokerGlobalnetSetting(ctx, brokerClient, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		inconsistencies = append(inconsistencies, compareSetting(GlobalnetSetting, clusterIDs, globalnetEnabled, expectedGlobalnet)...)
+
+		serviceDiscoveryEnabled, err := clustersWithServiceDiscovery(ctx, brokerClient, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		expectedServiceDiscovery, err := brokerServiceDiscoverySetting(ctx, brokerClient, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		inconsistencies = append(inconsistencies, compareSetting(ServiceDiscoverySetting, clusterIDs, serviceDiscoveryEnabled,
+			expectedServiceDiscovery)...)
+	}
+
+	return inconsistencies, nil
+}
+
+// compareSetting returns the clusters whose setting doesn't match the expected one; if none is given, the setting used by
+// most clusters is expected.
+func compareSetting(setting string, clusterIDs []string, enabled map[string]bool, expected *bool) []Inconsistency {
+	if expected == nil {
+		enabledCount := 0
+
+		for _, clusterID := range clusterIDs {
+			if enabled[clusterID] {
+				enabledCount++
+			}
+		}
+
+		majority := enabledCount*2 > len(clusterIDs)
+		expected = &majority
+	}
+
+	inconsistencies := []Inconsistency{}
+
+	for _, clusterID := range clusterIDs {
+		if enabled[clusterID] != *expected {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Expected:  *expected,
+				Actual:    enabled[clusterID],
+				ClusterID: clusterID,
+				Setting:   setting,
+			})
+		}
+	}
+
+	return inconsistencies
+}
+
+func brokerGlobalnetSetting(ctx context.Context, brokerClient controllerClient.Client, namespace string) (*bool, error) {
+	globalnetInfo, _, err := globalnet.GetGlobalNetworks(ctx, brokerClient, namespace)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	return &globalnetInfo.Enabled, nil
+}
+
+// brokerServiceDiscoverySetting returns whether the Broker resource in the given namespace enables service discovery; the
+// Broker resource is only visible with administrator access to the broker, so it may not be found.
+func brokerServiceDiscoverySetting(ctx context.Context, brokerClient controllerClient.Client, namespace string) (*bool, error) {
+	brokers := &operatorv1alpha1.BrokerList{}
+
+	err := brokerClient.List(ctx, brokers, controllerClient.InNamespace(namespace))
+	if apierrors.IsForbidden(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the Broker resources in namespace %q", namespace)
+	}
+
+	if len(brokers.Items) != 1 {
+		return nil, nil
+	}
+
+	enabled := false
+
+	for _, name := range brokers.Items[0].Spec.Components {
+		if name == component.ServiceDiscovery {
+			enabled = true
+		}
+	}
+
+	return &enabled, nil
+}
+
+// clustersWithServiceDiscovery returns the clusters whose service discovery agent synced ServiceImports or EndpointSlices
+// to the given broker namespace.
+func clustersWithServiceDiscovery(ctx context.Context, brokerClient controllerClient.Client, namespace string,
+) (map[string]bool, error) {
+	enabled := map[string]bool{}
+
+	serviceImports := &mcsv1a1.ServiceImportList{}
+
+	err := brokerClient.List(ctx, serviceImports, controllerClient.InNamespace(namespace),
+		controllerClient.HasLabels{lhconstants.MCSLabelSourceCluster})
+	if err != nil && !meta.IsNoMatchError(err) {
+		return nil, errors.Wrapf(err, "error listing the ServiceImports in namespace %q", namespace)
+	}
+
+	for i := range serviceImports.Items {
+		enabled[serviceImports.Items[i].Labels[lhconstants.MCSLabelSourceCluster]] = true
+	}
+
+	endpointSlices := &discovery.EndpointSliceList{}
+
+	err = brokerClient.List(ctx, endpointSlices, controllerClient.InNamespace(namespace),
+		controllerClient.HasLabels{lhconstants.MCSLabelSourceCluster})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the EndpointSlices in namespace %q", namespace)
+	}
+
+	for i := range endpointSlices.Items {
+		enabled[endpointSlices.Items[i].Labels[lhconstants.MCSLabelSourceCluster]] = true
+	}
+
+	return enabled, nil
+}
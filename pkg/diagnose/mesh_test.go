@@ -0,0 +1,153 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	lhconstants "github.com/submariner-io/lighthouse/pkg/constants"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/pkg/diagnose"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeScheme "k8s.io/client-go/kubernetes/scheme"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+)
+
+const brokerNamespace = "submariner-k8s-broker"
+
+var _ = Describe("CheckMeshConsistency", func() {
+	var objects []controllerClient.Object
+
+	BeforeEach(func() {
+		objects = []controllerClient.Object{
+			newBrokerCluster(brokerNamespace, "east", ""),
+			newBrokerCluster(brokerNamespace, "west", ""),
+			newSourceClusterObject(&mcsv1a1.ServiceImport{}, brokerNamespace, "nginx-east", "east"),
+			newSourceClusterObject(&discovery.EndpointSlice{}, brokerNamespace, "nginx-west", "west"),
+		}
+	})
+
+	check := func() []diagnose.Inconsistency {
+		scheme := runtime.NewScheme()
+		Expect(kubeScheme.AddToScheme(scheme)).To(Succeed())
+		Expect(submarinerv1.AddToScheme(scheme)).To(Succeed())
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(mcsv1a1.AddToScheme(scheme)).To(Succeed())
+
+		inconsistencies, err := diagnose.CheckMeshConsistency(context.TODO(),
+			fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build())
+		Expect(err).To(Succeed())
+
+		return inconsistencies
+	}
+
+	When("the broker records the expected settings", func() {
+		BeforeEach(func() {
+			configMap, err := globalnet.NewGlobalnetConfigMap(false, globalnet.DefaultGlobalnetCIDR, 0, brokerNamespace)
+			Expect(err).To(Succeed())
+
+			objects = append(objects, configMap, newBroker(brokerNamespace, component.Connectivity, component.ServiceDiscovery))
+		})
+
+		It("should report no inconsistencies if all the clusters match", func() {
+			Expect(check()).To(BeEmpty())
+		})
+
+		It("should report a cluster using globalnet when the broker doesn't", func() {
+			objects[1] = newBrokerCluster(brokerNamespace, "west", "242.1.0.0/16")
+
+			Expect(check()).To(ConsistOf(diagnose.Inconsistency{
+				Expected: false, Actual: true, ClusterID: "west", Setting: diagnose.GlobalnetSetting,
+			}))
+		})
+
+		It("should report a cluster without service discovery when the broker enables it", func() {
+			objects = append(objects, newBrokerCluster(brokerNamespace, "north", ""))
+
+			Expect(check()).To(ConsistOf(diagnose.Inconsistency{
+				Expected: true, Actual: false, ClusterID: "north", Setting: diagnose.ServiceDiscoverySetting,
+			}))
+		})
+
+		It("should report clusters with service discovery when the broker doesn't enable it", func() {
+			objects[len(objects)-1] = newBroker(brokerNamespace, component.Connectivity)
+
+			Expect(check()).To(ConsistOf(
+				diagnose.Inconsistency{Expected: false, Actual: true, ClusterID: "east", Setting: diagnose.ServiceDiscoverySetting},
+				diagnose.Inconsistency{Expected: false, Actual: true, ClusterID: "west", Setting: diagnose.ServiceDiscoverySetting},
+			))
+		})
+	})
+
+	When("the broker doesn't record the expected settings", func() {
+		It("should report the clusters differing from the majority", func() {
+			objects = append(objects, newBrokerCluster(brokerNamespace, "north", "242.2.0.0/16"))
+
+			Expect(check()).To(ConsistOf(
+				diagnose.Inconsistency{Expected: false, Actual: true, ClusterID: "north", Setting: diagnose.GlobalnetSetting},
+				diagnose.Inconsistency{Expected: true, Actual: false, ClusterID: "north", Setting: diagnose.ServiceDiscoverySetting},
+			))
+		})
+	})
+
+	When("clusters are registered in several broker namespaces", func() {
+		It("should check each namespace separately", func() {
+			objects = append(objects, newBrokerCluster("other-broker", "south", "242.3.0.0/16"))
+
+			Expect(check()).To(BeEmpty())
+		})
+	})
+})
+
+func newBrokerCluster(namespace, clusterID, globalCIDR string) *submarinerv1.Cluster {
+	cluster := &submarinerv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterID, Namespace: namespace},
+		Spec:       submarinerv1.ClusterSpec{ClusterID: clusterID},
+	}
+
+	if globalCIDR != "" {
+		cluster.Spec.GlobalCIDR = []string{globalCIDR}
+	}
+
+	return cluster
+}
+
+func newBroker(namespace string, components ...string) *operatorv1alpha1.Broker {
+	return &operatorv1alpha1.Broker{
+		ObjectMeta: metav1.ObjectMeta{Name: "submariner-broker", Namespace: namespace},
+		Spec:       operatorv1alpha1.BrokerSpec{Components: components},
+	}
+}
+
+func newSourceClusterObject(obj controllerClient.Object, namespace, name, clusterID string) controllerClient.Object {
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(map[string]string{lhconstants.MCSLabelSourceCluster: clusterID})
+
+	return obj
+}
@@ -0,0 +1,204 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/pods"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+)
+
+const routeSeparator = "---"
+
+var (
+	// The tunnel interfaces of the cable drivers which use one; libreswan uses IPsec policies without an interface. The
+	// vx-submariner interface belongs to the route agent and only carries intra-cluster traffic to the gateway.
+	tunnelInterfaces = map[string]string{
+		"vxlan":     "vxlan-tunnel",
+		"wireguard": "submariner",
+	}
+
+	linkMTURegexp   = regexp.MustCompile(`^\d+: ([^:@]+)(@[^:]*)?: .* mtu (\d+)`)
+	routeDevRegexp  = regexp.MustCompile(` dev (\S+)`)
+	routeMTUsRegexp = regexp.MustCompile(` mtu (?:lock )?(\d+)`)
+)
+
+// MTUChecker compares the gateway MTUs of the clusters it checks; its Check method is meant to be run on each cluster in
+// turn.
+type MTUChecker struct {
+	tunnelMTUs map[string]int
+}
+
+func NewMTUChecker() *MTUChecker {
+	return &MTUChecker{tunnelMTUs: map[string]int{}}
+}
+
+// Check determines the MTU of the active gateway's tunnel interface and of the underlay interface used to reach its peers,
+// and warns if the tunnel MTU doesn't leave room for the encapsulation or differs from that of the previously checked
+// clusters. Clusters where the MTUs can't be determined are skipped.
+func (c *MTUChecker) Check(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	status.Start("Checking the gateway MTUs")
+	defer status.End()
+
+	gateway, err := activeGateway(clusterInfo)
+	if err != nil {
+		status.Warning("Unable to find the active gateway, skipping the MTU check: %v", err)
+		return nil
+	}
+
+	cableDriver := gateway.Status.LocalEndpoint.Backend
+	tunnelInterface, hasTunnel := tunnelInterfaces[cableDriver]
+
+	repositoryInfo, err := clusterInfo.GetImageRepositoryInfo(firewallImageOverrides...)
+	if err != nil {
+		return status.Error(err, "Error determining repository information")
+	}
+
+	podOutput, err := pods.ScheduleAndAwaitCompletion(&pods.Config{
+		Name:                "query-mtu",
+		ClientSet:           clusterInfo.ClientProducer.ForKubernetes(),
+		Scheduling:          pods.Scheduling{ScheduleOn: pods.GatewayNode, Networking: pods.HostNetworking},
+		Namespace:           namespace,
+		Command:             mtuCommand(gateway),
+		ImageRepositoryInfo: *repositoryInfo,
+	})
+	if err != nil {
+		status.Warning("Unable to query the gateway interfaces, skipping the MTU check: %v", err)
+		return nil
+	}
+
+	linkOutput, routeOutput, _ := strings.Cut(podOutput, routeSeparator)
+	linkMTUs := parseLinkMTUs(linkOutput)
+	underlayInterface, underlayMTU := parseRouteMTU(routeOutput, linkMTUs)
+
+	if underlayMTU == 0 {
+		status.Warning("Unable to determine the underlay MTU of the gateway, skipping the MTU check")
+		return nil
+	}
+
+	status.Success("The underlay interface %q of the gateway has an MTU of %d", underlayInterface, underlayMTU)
+
+	if !hasTunnel {
+		status.Success("The %q cable driver doesn't use a tunnel interface, only the underlay MTU is reported", cableDriver)
+		return nil
+	}
+
+	tunnelMTU, found := linkMTUs[tunnelInterface]
+	if !found {
+		status.Warning("The tunnel interface %q wasn't found on the gateway, skipping the MTU comparison", tunnelInterface)
+		return nil
+	}
+
+	status.Success("The tunnel interface %q of the gateway has an MTU of %d", tunnelInterface, tunnelMTU)
+
+	if tunnelMTU >= underlayMTU {
+		status.Warning("The tunnel MTU (%d) doesn't leave room for the %s encapsulation within the underlay MTU (%d), "+
+			"packets will be fragmented or dropped", tunnelMTU, cableDriver, underlayMTU)
+	}
+
+	for otherCluster, otherMTU := range c.tunnelMTUs {
+		if otherMTU != tunnelMTU {
+			status.Warning("The tunnel MTU (%d) differs from that of cluster %q (%d)", tunnelMTU, otherCluster, otherMTU)
+		}
+	}
+
+	c.tunnelMTUs[clusterInfo.Name] = tunnelMTU
+
+	return nil
+}
+
+func activeGateway(clusterInfo *cluster.Info) (*submv1.Gateway, error) {
+	gateways, err := clusterInfo.GetGateways()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	for i := range gateways {
+		if gateways[i].Status.HAStatus == submv1.HAStatusActive {
+			return &gateways[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("none of the %d gateways is active", len(gateways))
+}
+
+// mtuCommand returns the command listing the interfaces and the route to the gateway's first peer, or the default route.
+func mtuCommand(gateway *submv1.Gateway) string {
+	route := "ip -o route show default"
+
+	for i := range gateway.Status.Connections {
+		connection := &gateway.Status.Connections[i]
+
+		peerIP := connection.UsingIP
+		if peerIP == "" {
+			peerIP = connection.Endpoint.PrivateIP
+		}
+
+		if peerIP != "" {
+			route = "ip -o route get " + peerIP
+			break
+		}
+	}
+
+	return fmt.Sprintf("ip -o link show; echo %s; %s", routeSeparator, route)
+}
+
+func parseLinkMTUs(output string) map[string]int {
+	mtus := map[string]int{}
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := linkMTURegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		if mtu, err := strconv.Atoi(matches[3]); err == nil {
+			mtus[matches[1]] = mtu
+		}
+	}
+
+	return mtus
+}
+
+// parseRouteMTU returns the interface used by the first route in the given output, and the route's MTU if it has one,
+// or the interface's.
+func parseRouteMTU(output string, linkMTUs map[string]int) (string, int) {
+	for _, line := range strings.Split(output, "\n") {
+		matches := routeDevRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		if mtuMatches := routeMTUsRegexp.FindStringSubmatch(line); mtuMatches != nil {
+			if mtu, err := strconv.Atoi(mtuMatches[1]); err == nil {
+				return matches[1], mtu
+			}
+		}
+
+		return matches[1], linkMTUs[matches[1]]
+	}
+
+	return "", 0
+}
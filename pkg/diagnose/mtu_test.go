@@ -0,0 +1,105 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/diagnose"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("MTUChecker", func() {
+	const (
+		links = "1: lo: <LOOPBACK,UP,LOWER_UP> mtu 65536 qdisc noqueue state UNKNOWN\n" +
+			"2: eth0@if7: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP\n"
+		route = "10.1.0.5 via 172.18.0.1 dev eth0 src 172.18.0.4 uid 0\n"
+	)
+
+	check := func(checker *diagnose.MTUChecker, name, cableDriver, output string) *cli.Recorder {
+		recorder, status := newRecorder()
+
+		clusterInfo := newClusterInfo(name, &operatorv1alpha1.SubmarinerSpec{}, func(pod *corev1.Pod) string {
+			Expect(podCommand(pod)).To(ContainSubstring("ip -o route get 10.1.0.5"))
+			return output
+		}, newGateway("gateway", &submarinerv1.GatewayStatus{
+			HAStatus:      submarinerv1.HAStatusActive,
+			LocalEndpoint: submarinerv1.EndpointSpec{Backend: cableDriver},
+			Connections:   []submarinerv1.Connection{{UsingIP: "10.1.0.5"}},
+		}))
+
+		Expect(checker.Check(clusterInfo, constants.OperatorNamespace, status)).To(Succeed())
+
+		return recorder
+	}
+
+	DescribeTable("checking a single cluster",
+		func(cableDriver, output, expectedStatus string) {
+			recorder := check(diagnose.NewMTUChecker(), "east", cableDriver, output)
+			Expect(statusesOf(recorder)).To(Equal([]string{expectedStatus}))
+		},
+		Entry("a tunnel MTU leaving room for the encapsulation", "vxlan",
+			links+"3: vxlan-tunnel: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1450 qdisc noqueue state UNKNOWN\n---\n"+route,
+			cli.CheckPassed),
+		Entry("a tunnel MTU as large as the underlay MTU", "wireguard",
+			links+"3: submariner: <POINTOPOINT,NOARP,UP,LOWER_UP> mtu 1500 qdisc noqueue state UNKNOWN\n---\n"+route,
+			cli.CheckWarning),
+		Entry("a route MTU lower than the tunnel MTU", "vxlan",
+			links+"3: vxlan-tunnel: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1450 qdisc noqueue state UNKNOWN\n---\n"+
+				"10.1.0.5 via 172.18.0.1 dev eth0 src 172.18.0.4 uid 0 mtu lock 1400\n",
+			cli.CheckWarning),
+		Entry("a cable driver without a tunnel interface", "libreswan", links+"---\n"+route, cli.CheckPassed),
+		Entry("a missing tunnel interface", "vxlan", links+"---\n"+route, cli.CheckWarning),
+		Entry("only the route agent's interface", "vxlan",
+			links+"3: vx-submariner: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1450 qdisc noqueue state UNKNOWN\n---\n"+route,
+			cli.CheckWarning),
+		Entry("an unknown underlay interface", "vxlan", links+"---\n", cli.CheckWarning),
+	)
+
+	When("the tunnel MTUs differ between clusters", func() {
+		It("should warn", func() {
+			checker := diagnose.NewMTUChecker()
+
+			recorder := check(checker, "east", "vxlan",
+				links+"3: vxlan-tunnel: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1450 qdisc noqueue state UNKNOWN\n---\n"+route)
+			Expect(statusesOf(recorder)).To(Equal([]string{cli.CheckPassed}))
+
+			recorder = check(checker, "west", "vxlan",
+				links+"3: vxlan-tunnel: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1400 qdisc noqueue state UNKNOWN\n---\n"+route)
+			Expect(statusesOf(recorder)).To(Equal([]string{cli.CheckWarning}))
+			Expect(recorder.Results()[0].Messages).To(ContainElement(ContainSubstring(fmt.Sprintf("cluster %q", "east"))))
+		})
+	})
+
+	When("there is no active gateway", func() {
+		It("should skip the check with a warning", func() {
+			recorder, status := newRecorder()
+
+			Expect(diagnose.NewMTUChecker().Check(newClusterInfo("east", &operatorv1alpha1.SubmarinerSpec{}, nil),
+				constants.OperatorNamespace, status)).To(Succeed())
+			Expect(statusesOf(recorder)).To(Equal([]string{cli.CheckWarning}))
+		})
+	})
+})
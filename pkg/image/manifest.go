@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadManifest reads an image manifest, mapping component names to fully-qualified images pinned by digest
+// (image@sha256:...), from the given YAML or JSON file. Only the given components may appear in the manifest, so that a
+// misspelt component is rejected instead of being silently ignored. The result can be used as the repository's image
+// overrides.
+func LoadManifest(filename string, validComponents []string) (map[string]string, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading the image manifest %q", filename)
+	}
+
+	manifest := map[string]string{}
+
+	err = yaml.Unmarshal(raw, &manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the image manifest %q", filename)
+	}
+
+	unknown := []string{}
+
+	for component, image := range manifest {
+		if !slices.Contains(validComponents, component) {
+			unknown = append(unknown, component)
+			continue
+		}
+
+		_, digest, found := strings.Cut(image, "@")
+		if !found || !strings.HasPrefix(digest, "sha256:") {
+			return nil, fmt.Errorf("the image %q for component %q in the image manifest isn't pinned by a sha256 digest",
+				image, component)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("the image manifest contains unknown components: %s; please choose from %q", strings.Join(unknown, ", "),
+			validComponents)
+	}
+
+	return manifest, nil
+}
+
+// CheckManifest verifies that the given image manifest provides an image for all the given components.
+func CheckManifest(manifest map[string]string, components []string) error {
+	missing := []string{}
+
+	for _, component := range components {
+		if manifest[component] == "" {
+			missing = append(missing, component)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("the image manifest doesn't provide images for the following components: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
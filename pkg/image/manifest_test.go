@@ -0,0 +1,91 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/image"
+)
+
+var (
+	validComponents = []string{"submariner-operator", "submariner-gateway", "submariner-globalnet"}
+	operatorImage   = "quay.io/submariner/submariner-operator@sha256:" + strings.Repeat("a", 64)
+	gatewayImage    = "quay.io/submariner/submariner-gateway@sha256:" + strings.Repeat("b", 64)
+)
+
+var _ = Describe("LoadManifest", func() {
+	writeManifest := func(content string) string {
+		filename := filepath.Join(GinkgoT().TempDir(), "manifest.yaml")
+		Expect(os.WriteFile(filename, []byte(content), 0o600)).To(Succeed())
+
+		return filename
+	}
+
+	DescribeTable("loading a valid manifest",
+		func(content string) {
+			manifest, err := image.LoadManifest(writeManifest(content), validComponents)
+			Expect(err).To(Succeed())
+			Expect(manifest).To(Equal(map[string]string{
+				"submariner-operator": operatorImage,
+				"submariner-gateway":  gatewayImage,
+			}))
+		},
+		Entry("in YAML", "submariner-operator: "+operatorImage+"\nsubmariner-gateway: "+gatewayImage+"\n"),
+		Entry("in JSON", `{"submariner-operator": "`+operatorImage+`", "submariner-gateway": "`+gatewayImage+`"}`),
+	)
+
+	DescribeTable("loading an invalid manifest",
+		func(content, expected string) {
+			_, err := image.LoadManifest(writeManifest(content), validComponents)
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("with an image pinned by tag", "submariner-operator: quay.io/submariner/submariner-operator:0.15.0\n",
+			"isn't pinned by a sha256 digest"),
+		Entry("with an image pinned by another digest", "submariner-operator: quay.io/submariner/submariner-operator@md5:abc\n",
+			"isn't pinned by a sha256 digest"),
+		Entry("with a misspelt component", "submariner-operator: "+operatorImage+"\nsubmariner-gatway: "+gatewayImage+"\n",
+			"unknown components: submariner-gatway"),
+		Entry("which isn't a map", "- "+operatorImage+"\n", "error parsing"),
+	)
+
+	When("the manifest doesn't exist", func() {
+		It("should return an error", func() {
+			_, err := image.LoadManifest(filepath.Join(GinkgoT().TempDir(), "missing.yaml"), validComponents)
+			Expect(err).To(MatchError(ContainSubstring("error reading")))
+		})
+	})
+})
+
+var _ = Describe("CheckManifest", func() {
+	manifest := map[string]string{"submariner-operator": operatorImage, "submariner-gateway": gatewayImage}
+
+	It("should accept a manifest providing all the components", func() {
+		Expect(image.CheckManifest(manifest, []string{"submariner-gateway", "submariner-operator"})).To(Succeed())
+	})
+
+	It("should list the missing components", func() {
+		Expect(image.CheckManifest(manifest, []string{"submariner-routeagent", "submariner-operator", "submariner-globalnet"})).To(
+			MatchError(HaveSuffix("following components: submariner-globalnet, submariner-routeagent")))
+	})
+})
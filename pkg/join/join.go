@@ -23,6 +23,7 @@ import (
 	goerrors "errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/reporter"
@@ -36,11 +37,15 @@ import (
 	"github.com/submariner-io/subctl/pkg/secret"
 	"github.com/submariner-io/subctl/pkg/version"
 	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/names"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+// brokerInfoWarningAge is the age beyond which broker information is considered suspiciously old.
+const brokerInfoWarningAge = 90 * 24 * time.Hour
+
 func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *cluster.Info, options *Options,
 	clientProducer client.Producer, status reporter.Interface,
 ) error {
@@ -54,7 +59,43 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 		return status.Error(err, "error validating custom CoreDNS config")
 	}
 
-	imageOverrides, err := cluster.MergeImageOverrides(nil, options.ImageOverrideArr)
+	err = deploy.ValidateSecretNameSuffix(options.SecretNameSuffix)
+	if err != nil {
+		return status.Error(err, "Invalid secret name suffix")
+	}
+
+	err = deploy.ValidateSecretMetadata(options.SecretLabels, options.SecretAnnotations)
+	if err != nil {
+		return status.Error(err, "Invalid secret labels or annotations")
+	}
+
+	if options.BrokerTokenTTL != 0 {
+		err = broker.ValidateClientTokenTTL(options.BrokerTokenTTL)
+		if err != nil {
+			return status.Error(err, "Invalid broker token TTL")
+		}
+	}
+
+	if options.DryRun && !brokerInfo.IsConnectivityEnabled() {
+		return status.Error(errors.New("dry-run mode is only supported when deploying connectivity"), "Unable to join")
+	}
+
+	err = broker.CheckBrokerInfoFreshness(brokerInfo, brokerInfoWarningAge)
+	if err != nil {
+		status.Warning("%s", err)
+	}
+
+	brokerProxy, err := broker.NewProxyFunc(options.BrokerHTTPProxy, options.BrokerHTTPSProxy)
+	if err != nil {
+		return status.Error(err, "Invalid broker proxy")
+	}
+
+	brokerCA, err := pinnedBrokerCA(brokerInfo, options)
+	if err != nil {
+		return status.Error(err, "Invalid broker CA")
+	}
+
+	imageOverrides, err := imageOverridesFrom(brokerInfo, options)
 	if err != nil {
 		return status.Error(err, "Error calculating image overrides")
 	}
@@ -62,7 +103,7 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 	status.Start("Gathering relevant information from Broker")
 	defer status.End()
 
-	brokerAdminConfig, err := brokerInfo.GetBrokerAdministratorConfig(ctx, !options.BrokerK8sSecure)
+	brokerAdminConfig, err := brokerInfo.GetBrokerAdministratorConfig(ctx, !options.BrokerK8sSecure, brokerProxy)
 	if err != nil {
 		return status.Error(err, "Error retrieving broker admin config")
 	}
@@ -73,13 +114,40 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 	}
 
 	brokerNamespace := string(brokerInfo.ClientToken.Data["namespace"])
+
+	if brokerInfo.IsConnectivityEnabled() {
+		err = deploy.CheckBrokerCableDrivers(ctx, brokerClientProducer.ForGeneral(), brokerNamespace, options.ClusterID,
+			options.CableDriver, status)
+		if err != nil {
+			status.Warning("Unable to check the cable drivers used by other clusters: %s", err)
+		}
+	}
+
+	if options.PreferredServer && options.MaxPreferredServers > 0 {
+		err = deploy.CheckPreferredServers(ctx, brokerClientProducer.ForGeneral(), brokerNamespace, options.ClusterID,
+			options.MaxPreferredServers, options.StrictPreferredServers, status)
+		if err != nil {
+			return status.Error(err, "Error checking the preferred server clusters")
+		}
+	}
+
+	if !options.SkipVersionCompatCheck {
+		err = deploy.CheckBrokerVersion(ctx, brokerClientProducer.ForGeneral(), brokerNamespace,
+			image.NewRepositoryInfo(options.Repository, options.ImageVersion, nil).Version, status)
+		if err != nil {
+			return status.Error(err, "Incompatible Submariner version")
+		}
+	}
+
 	netconfig := globalnet.Config{
 		ClusterID:   options.ClusterID,
 		GlobalCIDR:  options.GlobalnetCIDR,
 		ClusterSize: options.GlobalnetClusterSize,
 	}
 
-	if options.GlobalnetEnabled {
+	if options.GlobalnetEnabled && options.DryRun && netconfig.GlobalCIDR == "" {
+		status.Warning("The global CIDR isn't allocated in dry-run mode, specify it to include it in the output")
+	} else if options.GlobalnetEnabled {
 		err = globalnet.AllocateAndUpdateGlobalCIDRConfigMap(ctx, brokerClientProducer.ForGeneral(), brokerNamespace, &netconfig,
 			status)
 		if err != nil {
@@ -91,35 +159,41 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 
 	repositoryInfo := image.NewRepositoryInfo(options.Repository, options.ImageVersion, imageOverrides)
 
-	err = operator.Ensure(ctx, status, clientProducer, constants.OperatorNamespace, repositoryInfo.GetOperatorImage(), options.OperatorDebug)
-	if err != nil {
-		return status.Error(err, "Error deploying the operator")
+	if options.VerifyRepository {
+		err = deploy.VerifyRepositoryReachable(repositoryInfo.Name)
+		if err != nil {
+			return status.Error(err, "Error verifying the image repository")
+		}
 	}
 
-	status.Start("Creating SA for cluster")
-
-	brokerInfo.ClientToken, err = broker.CreateSAForCluster(ctx, brokerClientProducer.ForKubernetes(), options.ClusterID, brokerNamespace)
-	if err != nil {
-		return status.Error(err, "Error creating SA for cluster")
+	if !options.DryRun {
+		err = operator.Ensure(ctx, status, clientProducer, constants.OperatorNamespace, repositoryInfo.GetOperatorImage(),
+			options.OperatorDebug)
+		if err != nil {
+			return status.Error(err, "Error deploying the operator")
+		}
 	}
 
-	status.Start("Connecting to Broker")
-
-	// We need to connect to the broker in all cases
-	brokerSecret, err := secret.Ensure(ctx, clientProducer.ForKubernetes(), constants.OperatorNamespace, populateBrokerSecret(brokerInfo))
+	brokerSecret, err := connectToBroker(ctx, brokerInfo, brokerClientProducer, clientProducer, options, brokerNamespace, brokerCA,
+		status)
 	if err != nil {
-		return status.Error(err, "Error creating broker secret for cluster")
+		return err
 	}
 
 	if brokerInfo.IsConnectivityEnabled() {
 		status.Start("Deploying submariner")
 
-		err := deploy.Submariner(ctx, clientProducer, submarinerOptionsFrom(options), brokerInfo, brokerSecret, netconfig,
+		_, err := deploy.Submariner(ctx, clientProducer, submarinerOptionsFrom(options), brokerInfo, brokerSecret, netconfig,
 			repositoryInfo, status)
 		if err != nil {
 			return status.Error(err, "Error deploying the Submariner resource")
 		}
 
+		if options.DryRun {
+			status.Success("The resources which would be deployed were rendered, nothing was applied")
+			return nil
+		}
+
 		status.Success("Submariner is up and running")
 	} else if brokerInfo.IsServiceDiscoveryEnabled() {
 		status.Start("Deploying service discovery only")
@@ -136,6 +210,179 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 	return nil
 }
 
+// Join joins the cluster accessed through the given client producer to the broker described in the given broker
+// information file, in one call: the broker information is loaded and validated before any cluster access, then the
+// cluster is joined as with ClusterToBroker. The given options aren't modified.
+func Join(ctx context.Context, clientProducer client.Producer, brokerInfoPath string, options *Options, status reporter.Interface,
+) error {
+	status.Start("Loading the broker information from %q", brokerInfoPath)
+
+	brokerInfo, err := broker.ReadInfoFromFile(brokerInfoPath)
+	if err != nil {
+		return status.Error(err, "Error loading the broker information")
+	}
+
+	err = validateBrokerInfo(brokerInfo, options.ExistingPSKSecret == "")
+	if err != nil {
+		return status.Error(err, "Invalid broker information")
+	}
+
+	status.End()
+
+	joinOptions := *options
+
+	if joinOptions.CustomDomains == nil && brokerInfo.CustomDomains != nil {
+		joinOptions.CustomDomains = append([]string{}, *brokerInfo.CustomDomains...)
+	}
+
+	return ClusterToBroker(ctx, brokerInfo, &cluster.Info{Name: joinOptions.ClusterID, ClientProducer: clientProducer}, &joinOptions,
+		clientProducer, status)
+}
+
+func validateBrokerInfo(brokerInfo *broker.Info, needPSK bool) error {
+	if brokerInfo.BrokerURL == "" {
+		return errors.New("the broker URL is missing")
+	}
+
+	if brokerInfo.ClientToken == nil || len(brokerInfo.ClientToken.Data["token"]) == 0 {
+		return errors.New("the broker token is missing")
+	}
+
+	if len(brokerInfo.ClientToken.Data["namespace"]) == 0 {
+		return errors.New("the broker namespace is missing")
+	}
+
+	if needPSK && brokerInfo.IsConnectivityEnabled() && (brokerInfo.IPSecPSK == nil || len(brokerInfo.IPSecPSK.Data["psk"]) == 0) {
+		return errors.New("the IPsec PSK is missing")
+	}
+
+	return nil
+}
+
+// pinnedBrokerCA reads the broker CA bundle given in the options, if any, and pins it in the broker information.
+func pinnedBrokerCA(brokerInfo *broker.Info, options *Options) ([]byte, error) {
+	if options.BrokerCAFile == "" {
+		return nil, nil
+	}
+
+	if !options.BrokerK8sSecure {
+		return nil, errors.New("a broker CA bundle can't be used when the broker certificate isn't checked")
+	}
+
+	brokerCA, err := broker.ReadCABundle(options.BrokerCAFile)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	brokerInfo.PinCA(brokerCA)
+
+	return brokerCA, nil
+}
+
+// imageOverridesFrom returns the image overrides given by the image manifest, if any, and the explicit overrides,
+// which take precedence. The manifest must provide images for all the components which will be deployed, including
+// globalnet if it is enabled for this cluster.
+func imageOverridesFrom(brokerInfo *broker.Info, options *Options) (map[string]string, error) {
+	if options.ImageManifest == "" {
+		return cluster.MergeImageOverrides(nil, options.ImageOverrideArr) //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	manifest, err := image.LoadManifest(options.ImageManifest, cluster.ImageOverrideComponents())
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	imageOverrides, err := cluster.MergeImageOverrides(manifest, options.ImageOverrideArr)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap errors here.
+	}
+
+	required := []string{names.OperatorComponent}
+
+	if brokerInfo.IsConnectivityEnabled() {
+		required = append(required, names.GatewayComponent, names.RouteAgentComponent)
+
+		if options.GlobalnetEnabled {
+			required = append(required, names.GlobalnetComponent)
+		}
+	}
+
+	if brokerInfo.IsServiceDiscoveryEnabled() {
+		required = append(required, names.ServiceDiscoveryComponent, names.LighthouseCoreDNSComponent)
+	}
+
+	return imageOverrides, image.CheckManifest(imageOverrides, required) //nolint:wrapcheck // No need to wrap errors here.
+}
+
+func connectToBroker(ctx context.Context, brokerInfo *broker.Info, brokerClientProducer, clientProducer client.Producer,
+	options *Options, brokerNamespace string, brokerCA []byte, status reporter.Interface,
+) (*v1.Secret, error) {
+	if options.DryRun {
+		// Nothing is created, the broker secret is rendered with its name prefix
+		brokerSecret := populateBrokerSecret(brokerInfo, options.SecretNameSuffix)
+		brokerSecret.Name = brokerSecret.GenerateName
+
+		return brokerSecret, nil
+	}
+
+	status.Start("Creating SA for cluster")
+
+	var err error
+
+	brokerInfo.ClientToken, err = broker.CreateSAForCluster(ctx, brokerClientProducer.ForKubernetes(), options.ClusterID, brokerNamespace)
+	if err != nil {
+		return nil, status.Error(err, "Error creating SA for cluster")
+	}
+
+	if options.BrokerTokenTTL != 0 {
+		err = useBoundClientToken(ctx, brokerInfo, brokerClientProducer.ForKubernetes(), options, brokerNamespace, status)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if brokerCA != nil {
+		// The cluster's token comes with the broker's own CA, which mustn't replace the pinned one
+		brokerInfo.PinCA(brokerCA)
+	}
+
+	status.Start("Connecting to Broker")
+
+	// We need to connect to the broker in all cases
+	brokerSecret, err := secret.Ensure(ctx, clientProducer.ForKubernetes(), constants.OperatorNamespace,
+		populateBrokerSecret(brokerInfo, options.SecretNameSuffix), options.SecretLabels, options.SecretAnnotations)
+	if err != nil {
+		return nil, status.Error(err, "Error creating broker secret for cluster")
+	}
+
+	return brokerSecret, nil
+}
+
+func useBoundClientToken(ctx context.Context, brokerInfo *broker.Info, brokerClient kubernetes.Interface, options *Options,
+	brokerNamespace string, status reporter.Interface,
+) error {
+	token, supported, err := broker.RequestBoundClientToken(ctx, brokerClient, options.ClusterID, brokerNamespace,
+		options.BrokerTokenTTL)
+	if err != nil {
+		return status.Error(err, "Error requesting a bound token for cluster")
+	}
+
+	if !supported {
+		status.Warning("The broker doesn't support bound service account tokens, using a long-lived token instead")
+		return nil
+	}
+
+	status.Warning("Using a bound broker token expiring in %v, on %s. It isn't refreshed: the cluster will lose its broker "+
+		"connectivity when it expires, unless it is joined again before then. The long-lived token of the cluster's "+
+		"service account still exists on the broker", options.BrokerTokenTTL,
+		time.Now().Add(options.BrokerTokenTTL).Format(time.RFC3339))
+
+	brokerInfo.ClientToken = brokerInfo.ClientToken.DeepCopy()
+	brokerInfo.ClientToken.Data["token"] = []byte(token)
+
+	return nil
+}
+
 func submarinerOptionsFrom(joinOptions *Options) *deploy.SubmarinerOptions {
 	return &deploy.SubmarinerOptions{
 		PreferredServer:               joinOptions.PreferredServer,
@@ -147,8 +394,10 @@ func submarinerOptionsFrom(joinOptions *Options) *deploy.SubmarinerOptions {
 		LoadBalancerEnabled:           joinOptions.LoadBalancerEnabled,
 		HealthCheckEnabled:            joinOptions.HealthCheckEnabled,
 		NATTPort:                      joinOptions.NATTPort,
+		GatewayCount:                  joinOptions.GatewayCount,
 		HealthCheckInterval:           joinOptions.HealthCheckInterval,
 		HealthCheckMaxPacketLossCount: joinOptions.HealthCheckMaxPacketLossCount,
+		RetryBudget:                   joinOptions.RetryBudget,
 		ClusterID:                     joinOptions.ClusterID,
 		CableDriver:                   joinOptions.CableDriver,
 		CoreDNSCustomConfigMap:        joinOptions.CoreDNSCustomConfigMap,
@@ -158,6 +407,18 @@ func submarinerOptionsFrom(joinOptions *Options) *deploy.SubmarinerOptions {
 		ServiceCIDR:                   joinOptions.ServiceCIDR,
 		ClusterCIDR:                   joinOptions.ClusterCIDR,
 		BrokerK8sInsecure:             !joinOptions.BrokerK8sSecure,
+		VerifyKernelModules:           joinOptions.VerifyKernelModules,
+		VerifyKubeProxyMode:           joinOptions.VerifyKubeProxyMode,
+		OmitInlineBrokerFields:        joinOptions.OmitInlineBrokerFields,
+		SecretNameSuffix:              joinOptions.SecretNameSuffix,
+		ExistingPSKSecret:             joinOptions.ExistingPSKSecret,
+		StrictHealthCheck:             joinOptions.StrictHealthCheck,
+		SecretLabels:                  joinOptions.SecretLabels,
+		SecretAnnotations:             joinOptions.SecretAnnotations,
+		DryRun:                        joinOptions.DryRun,
+		VerifyReferencedObjects:       joinOptions.VerifyReferencedObjects,
+		WaitForReady:                  joinOptions.WaitForReady,
+		ReadyWaitTimeout:              joinOptions.ReadyWaitTimeout,
 	}
 }
 
@@ -194,15 +455,15 @@ func checkRequirements(kubeClient kubernetes.Interface, ignoreRequirements bool,
 	return status.Error(err, "unable to check version requirements")
 }
 
-func populateBrokerSecret(brokerInfo *broker.Info) *v1.Secret {
+func populateBrokerSecret(brokerInfo *broker.Info, secretNameSuffix string) *v1.Secret {
 	// We need to copy the broker token secret as an opaque secret to store it in the connecting cluster
-	return &v1.Secret{
+	return deploy.WithSecretNameSuffix(&v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: "broker-secret-",
 		},
 		Type: v1.SecretTypeOpaque,
 		Data: brokerInfo.ClientToken.Data,
-	}
+	}, secretNameSuffix)
 }
 
 func isValidCustomCoreDNSConfig(corednsCustomConfigMap string) error {
@@ -0,0 +1,223 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package join_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/join"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Join", func() {
+	var (
+		brokerInfo     *broker.Info
+		brokerInfoPath string
+		options        *join.Options
+		brokerServer   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		brokerInfo = &broker.Info{
+			BrokerURL: "https://broker.example.com:6443",
+			ClientToken: &v1.Secret{Data: map[string][]byte{
+				"namespace": []byte("submariner-k8s-broker"),
+				"token":     []byte("token"),
+			}},
+			IPSecPSK:   &v1.Secret{Data: map[string][]byte{"psk": []byte("psk")}},
+			Components: []string{"service-discovery", "connectivity"},
+		}
+		brokerInfoPath = filepath.Join(GinkgoT().TempDir(), broker.InfoFileName)
+		options = &join.Options{}
+
+		// The broker has no resources, which is enough to join in dry-run mode
+		brokerServer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+		}))
+		DeferCleanup(brokerServer.Close)
+
+		brokerInfo.BrokerURL = brokerServer.URL
+	})
+
+	writeBrokerInfo := func() string {
+		data, err := json.Marshal(brokerInfo)
+		Expect(err).To(Succeed())
+		Expect(os.WriteFile(brokerInfoPath, []byte(base64.URLEncoding.EncodeToString(data)), 0o600)).To(Succeed())
+
+		return brokerInfoPath
+	}
+
+	joinFromFile := func() error {
+		// The broker information is validated before any cluster access, so no client producer is needed
+		return join.Join(context.TODO(), nil, writeBrokerInfo(), options, reporter.Silent())
+	}
+
+	// tryJoinDryRun joins the cluster in dry-run mode and returns the rendered resources.
+	tryJoinDryRun := func() (string, error) {
+		options.ClusterID = "east"
+		options.DryRun = true
+		options.SkipVersionCompatCheck = true
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{Major: "1", Minor: "25"}
+
+		reader, writer, err := os.Pipe()
+		Expect(err).To(Succeed())
+
+		stdout := os.Stdout
+		os.Stdout = writer
+
+		err = join.Join(context.TODO(), &client.DefaultProducer{KubeClient: kubeClient}, writeBrokerInfo(), options,
+			reporter.Silent())
+
+		os.Stdout = stdout
+
+		Expect(writer.Close()).To(Succeed())
+
+		rendered, readErr := io.ReadAll(reader)
+		Expect(readErr).To(Succeed())
+
+		return string(rendered), err
+	}
+
+	joinDryRun := func() string {
+		rendered, err := tryJoinDryRun()
+		Expect(err).To(Succeed())
+
+		return rendered
+	}
+
+	When("the broker URL is missing", func() {
+		It("should return an error", func() {
+			brokerInfo.BrokerURL = ""
+			Expect(joinFromFile()).To(MatchError(ContainSubstring("URL")))
+		})
+	})
+
+	When("the broker token is missing", func() {
+		It("should return an error", func() {
+			delete(brokerInfo.ClientToken.Data, "token")
+			Expect(joinFromFile()).To(MatchError(ContainSubstring("token")))
+		})
+	})
+
+	When("the broker namespace is missing", func() {
+		It("should return an error", func() {
+			delete(brokerInfo.ClientToken.Data, "namespace")
+			Expect(joinFromFile()).To(MatchError(ContainSubstring("namespace")))
+		})
+	})
+
+	When("the IPsec PSK is missing", func() {
+		BeforeEach(func() {
+			brokerInfo.IPSecPSK = nil
+		})
+
+		It("should return an error", func() {
+			Expect(joinFromFile()).To(MatchError(ContainSubstring("PSK")))
+		})
+
+		Context("and an existing PSK secret is used", func() {
+			It("should join the cluster", func() {
+				options.ExistingPSKSecret = "my-psk"
+
+				Expect(joinDryRun()).To(ContainSubstring("ceIPSecPSKSecret: my-psk"))
+			})
+		})
+	})
+
+	When("the broker information is valid", func() {
+		It("should join the cluster to the broker", func() {
+			rendered := joinDryRun()
+			Expect(rendered).To(ContainSubstring("kind: Submariner"))
+			Expect(rendered).To(ContainSubstring("clusterID: east"))
+			Expect(rendered).To(ContainSubstring("brokerK8sApiServer: " + strings.TrimPrefix(brokerServer.URL, "https://")))
+		})
+
+		It("should use the broker's custom domains without modifying the options", func() {
+			brokerInfo.CustomDomains = &[]string{"example.com"}
+
+			Expect(joinDryRun()).To(ContainSubstring("- example.com"))
+			Expect(options.CustomDomains).To(BeNil())
+		})
+	})
+
+	When("an image manifest is given", func() {
+		writeManifest := func(components ...string) {
+			content := ""
+			for _, component := range components {
+				content += component + ": quay.io/submariner/" + component + "@sha256:" + strings.Repeat("a", 64) + "\n"
+			}
+
+			options.ImageManifest = filepath.Join(GinkgoT().TempDir(), "manifest.yaml")
+			Expect(os.WriteFile(options.ImageManifest, []byte(content), 0o600)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			brokerInfo.Components = []string{"connectivity"}
+			writeManifest(names.OperatorComponent, names.GatewayComponent, names.RouteAgentComponent)
+		})
+
+		It("should deploy the manifest's images", func() {
+			Expect(joinDryRun()).To(ContainSubstring("quay.io/submariner/submariner-gateway@sha256:"))
+		})
+
+		Context("and globalnet is enabled", func() {
+			BeforeEach(func() {
+				options.GlobalnetEnabled = true
+			})
+
+			It("should require the globalnet image", func() {
+				_, err := tryJoinDryRun()
+				Expect(err).To(MatchError(ContainSubstring(names.GlobalnetComponent)))
+			})
+
+			It("should join the cluster if the manifest provides it", func() {
+				writeManifest(names.OperatorComponent, names.GatewayComponent, names.RouteAgentComponent, names.GlobalnetComponent)
+
+				Expect(joinDryRun()).To(ContainSubstring("quay.io/submariner/submariner-globalnet@sha256:"))
+			})
+		})
+	})
+
+	When("the broker information file doesn't exist", func() {
+		It("should return an error", func() {
+			Expect(join.Join(context.TODO(), nil, brokerInfoPath, options, reporter.Silent())).ToNot(Succeed())
+		})
+	})
+})
@@ -18,6 +18,8 @@ limitations under the License.
 
 package join
 
+import "time"
+
 type Options struct {
 	PreferredServer               bool
 	ForceUDPEncaps                bool
@@ -31,10 +33,25 @@ type Options struct {
 	LoadBalancerEnabled           bool
 	HealthCheckEnabled            bool
 	BrokerK8sSecure               bool
+	VerifyKernelModules           bool
+	VerifyKubeProxyMode           bool
+	VerifyRepository              bool
+	SkipVersionCompatCheck        bool
+	OmitInlineBrokerFields        bool
+	StrictPreferredServers        bool
+	StrictHealthCheck             bool
+	DryRun                        bool
+	VerifyReferencedObjects       bool
+	WaitForReady                  bool
 	NATTPort                      int
+	MaxPreferredServers           int
+	GatewayCount                  int
 	GlobalnetClusterSize          uint
 	HealthCheckInterval           uint64
 	HealthCheckMaxPacketLossCount uint64
+	BrokerTokenTTL                time.Duration
+	RetryBudget                   time.Duration
+	ReadyWaitTimeout              time.Duration
 	ClusterID                     string
 	ServiceCIDR                   string
 	ClusterCIDR                   string
@@ -43,6 +60,14 @@ type Options struct {
 	ImageVersion                  string
 	CableDriver                   string
 	CoreDNSCustomConfigMap        string
+	SecretNameSuffix              string
+	ExistingPSKSecret             string
+	BrokerHTTPProxy               string
+	BrokerHTTPSProxy              string
+	BrokerCAFile                  string
+	ImageManifest                 string
 	CustomDomains                 []string
 	ImageOverrideArr              []string
+	SecretLabels                  map[string]string
+	SecretAnnotations             map[string]string
 }
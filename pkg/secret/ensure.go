@@ -20,17 +20,39 @@ package secret
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/resource"
 	"github.com/submariner-io/admiral/pkg/util"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
-func Ensure(ctx context.Context, client kubernetes.Interface, namespace string, secret *v1.Secret) (*v1.Secret, error) {
+// Ensure creates the given secret in the given namespace, replacing any existing secret with different contents. The given
+// labels and annotations, if any, are added to the secret's own. An existing secret's labels and annotations aren't
+// compared, the given ones are merged into them instead, so that changing them doesn't replace the secret and those set by
+// others are preserved.
+func Ensure(ctx context.Context, client kubernetes.Interface, namespace string, secret *v1.Secret,
+	labels, annotations map[string]string,
+) (*v1.Secret, error) {
+	secret = secret.DeepCopy()
+
+	existing, err := client.CoreV1().Secrets(namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if err == nil {
+		secret.Labels = existing.Labels
+		secret.Annotations = existing.Annotations
+	} else if apierrors.IsNotFound(err) {
+		secret.Labels = withEntries(secret.Labels, labels)
+		secret.Annotations = withEntries(secret.Annotations, annotations)
+	} else {
+		return nil, errors.Wrap(err, "error retrieving the existing secret")
+	}
+
 	//nolint:wrapcheck // No need to wrap errors here
 	object, err := util.CreateAnew(ctx, &resource.InterfaceFuncs{
 		GetFunc: func(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error) {
@@ -43,6 +65,63 @@ func Ensure(ctx context.Context, client kubernetes.Interface, namespace string,
 			return client.CoreV1().Secrets(namespace).Delete(ctx, name, options)
 		},
 	}, secret, metav1.CreateOptions{}, metav1.DeleteOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating secret")
+	}
+
+	return mergeMetadata(ctx, client, namespace, object.(*v1.Secret), labels, annotations)
+}
+
+// mergeMetadata adds the given labels and annotations to those of the given secret with a merge patch, if they're missing.
+func mergeMetadata(ctx context.Context, client kubernetes.Interface, namespace string, secret *v1.Secret,
+	labels, annotations map[string]string,
+) (*v1.Secret, error) {
+	metadata := map[string]interface{}{}
+
+	if !containsEntries(secret.Labels, labels) {
+		metadata["labels"] = labels
+	}
+
+	if !containsEntries(secret.Annotations, annotations) {
+		metadata["annotations"] = annotations
+	}
+
+	if len(metadata) == 0 {
+		return secret, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshalling the secret metadata")
+	}
+
+	patched, err := client.CoreV1().Secrets(namespace).Patch(ctx, secret.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+
+	return patched, errors.Wrap(err, "error updating the secret metadata")
+}
+
+func containsEntries(entries, expected map[string]string) bool {
+	for key, value := range expected {
+		if existing, found := entries[key]; !found || existing != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func withEntries(to, from map[string]string) map[string]string {
+	if len(from) == 0 {
+		return to
+	}
+
+	if to == nil {
+		to = make(map[string]string, len(from))
+	}
+
+	for key, value := range from {
+		to[key] = value
+	}
 
-	return object.(*v1.Secret), errors.Wrap(err, "error creating secret")
+	return to
 }
@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/secret"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Ensure", func() {
+	const versionLabel = "subctl.submariner.io/version"
+
+	var (
+		kubeClient *fake.Clientset
+		deleted    int
+	)
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset()
+		deleted = 0
+
+		kubeClient.PrependReactor("delete", "secrets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			deleted++
+			return false, nil, nil
+		})
+	})
+
+	newSecret := func(psk string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: pskSecretName},
+			Data:       map[string][]byte{"psk": []byte(psk)},
+		}
+	}
+
+	ensure := func(psk, version string) *v1.Secret {
+		ensured, err := secret.Ensure(context.TODO(), kubeClient, submarinerNamespace, newSecret(psk),
+			map[string]string{versionLabel: version}, nil)
+		Expect(err).To(Succeed())
+
+		return ensured
+	}
+
+	getSecret := func() *v1.Secret {
+		existing, err := kubeClient.CoreV1().Secrets(submarinerNamespace).Get(context.TODO(), pskSecretName, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+
+		return existing
+	}
+
+	addUserAnnotation := func() {
+		existing := getSecret()
+		existing.Annotations = map[string]string{"owner": "team-a"}
+
+		_, err := kubeClient.CoreV1().Secrets(submarinerNamespace).Update(context.TODO(), existing, metav1.UpdateOptions{})
+		Expect(err).To(Succeed())
+	}
+
+	It("should create the secret with the given labels", func() {
+		ensured := ensure("secret", "v0.15.0")
+		Expect(ensured.Labels).To(HaveKeyWithValue(versionLabel, "v0.15.0"))
+		Expect(getSecret().Labels).To(HaveKeyWithValue(versionLabel, "v0.15.0"))
+	})
+
+	When("the existing secret has the same contents but other labels", func() {
+		It("should merge the labels without replacing the secret or clobbering its annotations", func() {
+			ensure("secret", "v0.15.0")
+			addUserAnnotation()
+
+			ensured := ensure("secret", "v0.15.1")
+			Expect(deleted).To(BeZero())
+			Expect(ensured.Labels).To(HaveKeyWithValue(versionLabel, "v0.15.1"))
+			Expect(getSecret().Labels).To(HaveKeyWithValue(versionLabel, "v0.15.1"))
+			Expect(getSecret().Annotations).To(HaveKeyWithValue("owner", "team-a"))
+		})
+	})
+
+	When("the existing secret has different contents", func() {
+		It("should replace the secret, keeping its annotations", func() {
+			ensure("secret", "v0.15.0")
+			addUserAnnotation()
+
+			ensure("other", "v0.15.1")
+			Expect(deleted).To(Equal(1))
+			Expect(getSecret().Data).To(HaveKeyWithValue("psk", []byte("other")))
+			Expect(getSecret().Labels).To(HaveKeyWithValue(versionLabel, "v0.15.1"))
+			Expect(getSecret().Annotations).To(HaveKeyWithValue("owner", "team-a"))
+		})
+	})
+})
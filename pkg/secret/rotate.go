@@ -0,0 +1,137 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const ipsecPSKLength = 48
+
+// RotateIPSecPSK generates a new IPsec PSK and applies it to the Submariner deployment in each of the given clusters:
+// the PSK secret referenced by CeIPSecPSKSecret is updated and the gateways restarted, or CeIPSecPSK is updated in
+// place. All the clusters are checked before anything is changed; once the rotation has started, every cluster is
+// attempted and the failures are reported individually. The new PSK is returned so that it can be saved in the broker
+// information used for future joins; it is returned along with the error if some clusters failed, but shouldn't be saved
+// then since the clusters no longer share a PSK.
+func RotateIPSecPSK(ctx context.Context, clusterInfos []*cluster.Info, status reporter.Interface) ([]byte, error) {
+	for _, clusterInfo := range clusterInfos {
+		if clusterInfo.Submariner == nil {
+			return nil, status.Error(errors.New("Submariner is not installed"), "Unable to rotate the IPsec PSK in cluster %q",
+				clusterInfo.Name)
+		}
+	}
+
+	psk := make([]byte, ipsecPSKLength)
+
+	_, err := rand.Read(psk)
+	if err != nil {
+		return nil, status.Error(err, "Error generating a new IPsec PSK")
+	}
+
+	var rotationErrors []error
+
+	for _, clusterInfo := range clusterInfos {
+		status.Start("Rotating the IPsec PSK in cluster %q", clusterInfo.Name)
+
+		err := rotateIPSecPSKInCluster(ctx, clusterInfo, psk)
+		if err != nil {
+			rotationErrors = append(rotationErrors, errors.Wrapf(err, "cluster %q", clusterInfo.Name))
+		}
+
+		_ = status.Error(err, "Error rotating the IPsec PSK in cluster %q", clusterInfo.Name)
+		status.End()
+	}
+
+	return psk, errors.Wrap(k8serrors.NewAggregate(rotationErrors), "error rotating the IPsec PSK")
+}
+
+func rotateIPSecPSKInCluster(ctx context.Context, clusterInfo *cluster.Info, psk []byte) error {
+	spec := &clusterInfo.Submariner.Spec
+
+	if spec.CeIPSecPSKSecret != "" {
+		kubeClient := clusterInfo.ClientProducer.ForKubernetes()
+
+		//nolint:wrapcheck // No need to wrap errors here.
+		err := util.MustUpdate(ctx, &resource.InterfaceFuncs{
+			GetFunc: func(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error) {
+				return kubeClient.CoreV1().Secrets(spec.Namespace).Get(ctx, name, options)
+			},
+			UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+				return kubeClient.CoreV1().Secrets(spec.Namespace).Update(ctx, obj.(*v1.Secret), options)
+			},
+		}, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: spec.CeIPSecPSKSecret}},
+			func(existing runtime.Object) (runtime.Object, error) {
+				secret := existing.(*v1.Secret)
+				secret.Data = map[string][]byte{"psk": psk}
+				secret.StringData = nil
+
+				return secret, nil
+			})
+		if err != nil {
+			return errors.Wrapf(err, "error updating the IPsec PSK secret %q", spec.CeIPSecPSKSecret)
+		}
+
+		// A stale inline PSK would otherwise be picked up again if the secret reference were dropped
+		if spec.CeIPSecPSK != "" {
+			err = updateInlineIPSecPSK(ctx, clusterInfo, "")
+			if err != nil {
+				return err
+			}
+		}
+
+		// The gateways only read the secret on startup, restart them so the tunnels are re-established with the new PSK
+		err = kubeClient.CoreV1().Pods(spec.Namespace).DeleteCollection(ctx, metav1.DeleteOptions{},
+			metav1.ListOptions{LabelSelector: "app=" + names.GatewayComponent})
+
+		return errors.Wrap(err, "error restarting the gateways")
+	}
+
+	if spec.CeIPSecPSK != "" {
+		return updateInlineIPSecPSK(ctx, clusterInfo, base64.StdEncoding.EncodeToString(psk))
+	}
+
+	return errors.New("the Submariner resource doesn't reference an IPsec PSK")
+}
+
+func updateInlineIPSecPSK(ctx context.Context, clusterInfo *cluster.Info, psk string) error {
+	//nolint:wrapcheck // No need to wrap errors here.
+	err := util.MustUpdate(ctx, resource.ForControllerClient(clusterInfo.ClientProducer.ForGeneral(),
+		clusterInfo.Submariner.Namespace, &operatorv1alpha1.Submariner{}), clusterInfo.Submariner,
+		func(existing runtime.Object) (runtime.Object, error) {
+			existing.(*operatorv1alpha1.Submariner).Spec.CeIPSecPSK = psk
+			return existing, nil
+		})
+
+	return errors.Wrap(err, "error updating the Submariner resource")
+}
@@ -0,0 +1,145 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret_test
+
+import (
+	"context"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/secret"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	submarinerNamespace = "submariner-operator"
+	pskSecretName       = "submariner-ipsec-psk"
+)
+
+var _ = Describe("RotateIPSecPSK", func() {
+	var (
+		submariner *operatorv1alpha1.Submariner
+		clusters   []*cluster.Info
+	)
+
+	newCluster := func(name string, submariner *operatorv1alpha1.Submariner, objects ...runtime.Object) *cluster.Info {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		return &cluster.Info{
+			Name: name,
+			ClientProducer: &client.DefaultProducer{
+				KubeClient:    fake.NewSimpleClientset(objects...),
+				GeneralClient: fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(submariner).Build(),
+			},
+			Submariner: submariner,
+		}
+	}
+
+	getSubmariner := func(clusterInfo *cluster.Info) *operatorv1alpha1.Submariner {
+		existing := &operatorv1alpha1.Submariner{}
+		Expect(clusterInfo.ClientProducer.ForGeneral().Get(context.TODO(), controllerClient.ObjectKeyFromObject(submariner),
+			existing)).To(Succeed())
+
+		return existing
+	}
+
+	BeforeEach(func() {
+		submariner = &operatorv1alpha1.Submariner{
+			ObjectMeta: metav1.ObjectMeta{Name: "submariner", Namespace: submarinerNamespace},
+			Spec: operatorv1alpha1.SubmarinerSpec{
+				Namespace:  submarinerNamespace,
+				CeIPSecPSK: base64.StdEncoding.EncodeToString([]byte("old")),
+			},
+		}
+	})
+
+	When("the Submariner resource has an inline PSK", func() {
+		BeforeEach(func() {
+			clusters = []*cluster.Info{newCluster("east", submariner)}
+		})
+
+		It("should update the inline PSK", func() {
+			psk, err := secret.RotateIPSecPSK(context.TODO(), clusters, reporter.Silent())
+			Expect(err).To(Succeed())
+			Expect(getSubmariner(clusters[0]).Spec.CeIPSecPSK).To(Equal(base64.StdEncoding.EncodeToString(psk)))
+		})
+	})
+
+	When("the Submariner resource references a PSK secret", func() {
+		BeforeEach(func() {
+			submariner.Spec.CeIPSecPSKSecret = pskSecretName
+			clusters = []*cluster.Info{newCluster("east", submariner, &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: pskSecretName, Namespace: submarinerNamespace},
+				Data:       map[string][]byte{"psk": []byte("old")},
+			})}
+		})
+
+		It("should update the secret and clear the inline PSK", func() {
+			psk, err := secret.RotateIPSecPSK(context.TODO(), clusters, reporter.Silent())
+			Expect(err).To(Succeed())
+
+			pskSecret, err := clusters[0].ClientProducer.ForKubernetes().CoreV1().Secrets(submarinerNamespace).Get(context.TODO(),
+				pskSecretName, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(pskSecret.Data).To(Equal(map[string][]byte{"psk": psk}))
+
+			Expect(getSubmariner(clusters[0]).Spec.CeIPSecPSK).To(BeEmpty())
+		})
+	})
+
+	When("the rotation fails in one of the clusters", func() {
+		BeforeEach(func() {
+			failing := submariner.DeepCopy()
+			failing.Spec.CeIPSecPSKSecret = pskSecretName
+
+			clusters = []*cluster.Info{newCluster("east", submariner), newCluster("west", failing)}
+		})
+
+		It("should rotate the other clusters and return the error along with the PSK", func() {
+			psk, err := secret.RotateIPSecPSK(context.TODO(), clusters, reporter.Silent())
+			Expect(err).To(MatchError(ContainSubstring(`cluster "west"`)))
+			Expect(psk).ToNot(BeNil())
+			Expect(getSubmariner(clusters[0]).Spec.CeIPSecPSK).To(Equal(base64.StdEncoding.EncodeToString(psk)))
+		})
+	})
+
+	When("Submariner isn't installed in one of the clusters", func() {
+		BeforeEach(func() {
+			clusters = []*cluster.Info{newCluster("east", submariner), {Name: "west"}}
+		})
+
+		It("should not change any cluster", func() {
+			psk, err := secret.RotateIPSecPSK(context.TODO(), clusters, reporter.Silent())
+			Expect(err).To(HaveOccurred())
+			Expect(psk).To(BeNil())
+			Expect(getSubmariner(clusters[0]).Spec.CeIPSecPSK).To(Equal(submariner.Spec.CeIPSecPSK))
+		})
+	})
+})
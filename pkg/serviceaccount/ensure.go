@@ -140,7 +140,7 @@ func EnsureSecretFromSA(ctx context.Context, client kubernetes.Interface, saName
 			Type: corev1.SecretTypeServiceAccountToken,
 		}
 
-		saSecret, err = secret.Ensure(ctx, client, newSecret.Namespace, newSecret)
+		saSecret, err = secret.Ensure(ctx, client, newSecret.Namespace, newSecret, nil, nil)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create secret for ServiceAccount %v", saName)
 		}
@@ -20,29 +20,110 @@ package submarinercr
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/resource"
 	"github.com/submariner-io/admiral/pkg/util"
 	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/names"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func Ensure(ctx context.Context, client controllerClient.Client, namespace string, submarinerSpec *operatorv1alpha1.SubmarinerSpec) error {
-	submarinerCR := &operatorv1alpha1.Submariner{
+// Ensure creates the Submariner resource, replacing any existing resource with a different spec. The given labels and
+// annotations are merged into an existing resource's own rather than compared, so that changing them doesn't replace the
+// resource and those set by users are preserved.
+func Ensure(ctx context.Context, client controllerClient.Client, namespace string, submarinerSpec *operatorv1alpha1.SubmarinerSpec,
+	labels, annotations map[string]string,
+) (*operatorv1alpha1.Submariner, error) {
+	submarinerCR := newSubmariner(namespace, submarinerSpec, labels, annotations)
+
+	existing := &operatorv1alpha1.Submariner{}
+
+	err := client.Get(ctx, controllerClient.ObjectKeyFromObject(submarinerCR), existing)
+	if err == nil {
+		submarinerCR.Labels = existing.Labels
+		submarinerCR.Annotations = existing.Annotations
+	} else if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "error retrieving the existing Submariner resource")
+	}
+
+	propagationPolicy := metav1.DeletePropagationForeground
+
+	object, err := util.CreateAnew(ctx, resource.ForControllerClient(client, namespace, &operatorv1alpha1.Submariner{}),
+		submarinerCR, metav1.CreateOptions{}, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating Submariner resource")
+	}
+
+	return mergeMetadata(ctx, client, object.(*operatorv1alpha1.Submariner), labels, annotations)
+}
+
+// mergeMetadata adds the given labels and annotations to those of the given Submariner resource with a merge patch, if
+// they're missing.
+func mergeMetadata(ctx context.Context, client controllerClient.Client, submariner *operatorv1alpha1.Submariner,
+	labels, annotations map[string]string,
+) (*operatorv1alpha1.Submariner, error) {
+	patched := submariner.DeepCopy()
+	patched.Labels = withEntries(patched.Labels, labels)
+	patched.Annotations = withEntries(patched.Annotations, annotations)
+
+	if reflect.DeepEqual(patched.Labels, submariner.Labels) && reflect.DeepEqual(patched.Annotations, submariner.Annotations) {
+		return submariner, nil
+	}
+
+	err := client.Patch(ctx, patched, controllerClient.MergeFrom(submariner))
+
+	return patched, errors.Wrap(err, "error updating the Submariner resource metadata")
+}
+
+// Create creates the Submariner resource, failing if it already exists.
+func Create(ctx context.Context, client controllerClient.Client, namespace string, submarinerSpec *operatorv1alpha1.SubmarinerSpec,
+	labels, annotations map[string]string,
+) (*operatorv1alpha1.Submariner, error) {
+	submarinerCR := newSubmariner(namespace, submarinerSpec, labels, annotations)
+
+	err := client.Create(ctx, submarinerCR)
+	if apierrors.IsAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "a Submariner resource already exists in namespace %q", namespace)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating Submariner resource")
+	}
+
+	return submarinerCR, nil
+}
+
+func newSubmariner(namespace string, submarinerSpec *operatorv1alpha1.SubmarinerSpec, labels, annotations map[string]string,
+) *operatorv1alpha1.Submariner {
+	return &operatorv1alpha1.Submariner{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      names.SubmarinerCrName,
-			Namespace: namespace,
+			Name:        names.SubmarinerCrName,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: *submarinerSpec,
 	}
+}
 
-	propagationPolicy := metav1.DeletePropagationForeground
+func withEntries(to, from map[string]string) map[string]string {
+	if len(from) == 0 {
+		return to
+	}
 
-	_, err := util.CreateAnew(ctx, resource.ForControllerClient(client, namespace, &operatorv1alpha1.Submariner{}),
-		submarinerCR, metav1.CreateOptions{}, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+	merged := make(map[string]string, len(to)+len(from))
+
+	for key, value := range to {
+		merged[key] = value
+	}
+
+	for key, value := range from {
+		merged[key] = value
+	}
 
-	return errors.Wrap(err, "error creating Submariner resource")
+	return merged
 }
@@ -46,29 +46,54 @@ import (
 	controller "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const componentReadyTimeout = time.Minute * 2
+const (
+	componentReadyTimeout    = time.Minute * 2
+	namespaceDeletionTimeout = time.Minute * 5
+)
+
+type Options struct {
+	// BrokerRetryBudget, if set, is how long broker operations failing with transient errors are retried for.
+	BrokerRetryBudget time.Duration
+	// RemoveNamespace requests that the Submariner namespace be removed and waited for; the removal is refused if
+	// workloads or services not managed by Submariner remain in the namespace.
+	RemoveNamespace bool
+	// RemoveCRDs requests that the Multicluster Services API CRDs be removed along with the Submariner CRDs; these may be
+	// shared with other implementations of the API, so they're only removed on request.
+	RemoveCRDs bool
+	// Force requests that the finalizers still blocking the deletion of the Submariner resources after the timeout be
+	// removed, even if the operator is running.
+	Force bool
+	// DeletionTimeout, if set, is how long the deletion of the Submariner resources is awaited before checking for the
+	// finalizers blocking it; it defaults to two and a half minutes.
+	DeletionTimeout time.Duration
+}
 
-func All(clients client.Producer, clusterName, submarinerNamespace string,
+const (
+	submarinerCRDSuffix = ".submariner.io"
+	mcsCRDSuffix        = ".multicluster.x-k8s.io"
+)
+
+func All(clients client.Producer, clusterName, submarinerNamespace string, options Options,
 	status reporter.Interface,
 ) error {
-	found, err := ensureSubmarinerDeleted(clients, clusterName, submarinerNamespace, status)
+	found, err := ensureSubmarinerDeleted(clients, clusterName, submarinerNamespace, options, status)
 	if err != nil {
 		return err
 	}
 
 	if !found {
-		err = ensureServiceDiscoveryDeleted(clients, clusterName, submarinerNamespace, status)
+		err = ensureServiceDiscoveryDeleted(clients, clusterName, submarinerNamespace, options, status)
 		if err != nil {
 			return err
 		}
 	}
 
-	brokerNS, err := findBrokerNamespace(clients.ForGeneral(), clusterName, status)
+	brokerNS, err := findBrokerNamespace(clients.ForGeneral(), clusterName, options.BrokerRetryBudget, status)
 	if err != nil {
 		return err
 	}
 
-	deleted, err := deleteBrokerIfUnused(clients, brokerNS, clusterName, status)
+	deleted, err := deleteBrokerIfUnused(clients, brokerNS, clusterName, options.BrokerRetryBudget, status)
 	if err != nil {
 		return err
 	}
@@ -79,23 +104,78 @@ func All(clients client.Producer, clusterName, submarinerNamespace string,
 	}
 
 	if deleted {
-		status.Start("Deleting the Submariner namespace %q on cluster %q", submarinerNamespace, clusterName)
-		defer status.End()
+		err = deleteSubmarinerNamespace(clients, clusterName, submarinerNamespace, options.RemoveNamespace, status)
+		if err != nil {
+			return err
+		}
 
-		err = clients.ForKubernetes().CoreV1().Namespaces().Delete(context.TODO(), submarinerNamespace, metav1.DeleteOptions{})
-		if err != nil && !apierrors.IsNotFound(err) {
-			return status.Error(err, "Error deleting the Submariner namespace")
+		crdSuffixes := []string{submarinerCRDSuffix}
+		if options.RemoveCRDs {
+			crdSuffixes = append(crdSuffixes, mcsCRDSuffix)
 		}
 
-		err = deleteCRDs(clients.ForGeneral(), clusterName, status)
+		err = deleteCRDs(clients.ForGeneral(), clusterName, crdSuffixes, status)
 		if err != nil {
 			return err
 		}
+	} else {
+		if options.RemoveNamespace {
+			status.Warning("The Submariner namespace %q is still needed by the broker on cluster %q - not removing it",
+				submarinerNamespace, clusterName)
+		}
+
+		if options.RemoveCRDs {
+			status.Warning("The custom resource definitions are still needed by the broker on cluster %q - not removing them",
+				clusterName)
+		}
 	}
 
 	return unlabelGatewayNodes(clients, clusterName, status)
 }
 
+func deleteSubmarinerNamespace(clients client.Producer, clusterName, namespace string, awaitRemoval bool,
+	status reporter.Interface,
+) error {
+	status.Start("Deleting the Submariner namespace %q on cluster %q", namespace, clusterName)
+	defer status.End()
+
+	namespaces := clients.ForKubernetes().CoreV1().Namespaces()
+
+	if awaitRemoval {
+		foreign, err := findNonSubmarinerResources(context.TODO(), clients.ForKubernetes(), namespace)
+		if err != nil {
+			return status.Error(err, "Error checking the contents of the Submariner namespace")
+		}
+
+		if len(foreign) > 0 {
+			return status.Error(fmt.Errorf("the namespace contains resources not managed by Submariner: %s",
+				strings.Join(foreign, ", ")), "Refusing to delete the Submariner namespace %q", namespace)
+		}
+	}
+
+	err := namespaces.Delete(context.TODO(), namespace, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return status.Error(err, "Error deleting the Submariner namespace")
+	}
+
+	if !awaitRemoval {
+		return nil
+	}
+
+	status.Start("Waiting for the Submariner namespace %q to terminate", namespace)
+
+	err = wait.PollImmediate(2*time.Second, namespaceDeletionTimeout, func() (bool, error) {
+		_, err := namespaces.Get(context.TODO(), namespace, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err //nolint:wrapcheck // No need to wrap
+	})
+
+	return status.Error(err, "Error waiting for the Submariner namespace to terminate")
+}
+
 func unlabelGatewayNodes(clients client.Producer, clusterName string, status reporter.Interface) error {
 	status.Start("Unlabeling gateway nodes on cluster %q", clusterName)
 	defer status.End()
@@ -130,7 +210,7 @@ func unlabelGatewayNodes(clients client.Producer, clusterName string, status rep
 	return nil
 }
 
-func deleteCRDs(controllerClient controller.Client, clusterName string, status reporter.Interface) error {
+func deleteCRDs(controllerClient controller.Client, clusterName string, suffixes []string, status reporter.Interface) error {
 	status.Start("Deleting the Submariner custom resource definitions on cluster %q", clusterName)
 	defer status.End()
 
@@ -142,7 +222,7 @@ func deleteCRDs(controllerClient controller.Client, clusterName string, status r
 	}
 
 	for i := range list.Items {
-		if !strings.HasSuffix(list.Items[i].Name, ".submariner.io") {
+		if !hasAnySuffix(list.Items[i].Name, suffixes) {
 			continue
 		}
 
@@ -157,6 +237,16 @@ func deleteCRDs(controllerClient controller.Client, clusterName string, status r
 	return nil
 }
 
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func deleteClusterRolesAndBindings(clients client.Producer, clusterName string, status reporter.Interface,
 	keepOperator bool,
 ) error {
@@ -189,7 +279,10 @@ func deleteClusterRolesAndBindings(clients client.Producer, clusterName string,
 	return nil
 }
 
-func ensureSubmarinerDeleted(clients client.Producer, clusterName, namespace string, status reporter.Interface) (bool, error) {
+// ensureSubmarinerDeleted deletes the Submariner resource, if any, and then its cluster's resources from the broker.
+func ensureSubmarinerDeleted(clients client.Producer, clusterName, namespace string, options Options,
+	status reporter.Interface,
+) (bool, error) {
 	defer status.End()
 
 	status.Start("Checking if the connectivity component is installed on cluster %q", clusterName)
@@ -213,12 +306,21 @@ func ensureSubmarinerDeleted(clients client.Producer, clusterName, namespace str
 
 	status.Start("Deleting the Submariner resource - this may take some time")
 
-	err = ensureDeleted(clients, submariner, status)
+	err = ensureDeleted(clients, submariner, "Submariner", options, status)
+	if err != nil {
+		return true, status.Error(err, "Error deleting Submariner resource %q", submariner.Name)
+	}
+
+	status.End()
+
+	deleteClusterFromBroker(clients.ForKubernetes(), submariner, options.BrokerRetryBudget, status)
 
-	return true, status.Error(err, "Error deleting Submariner resource %q", submariner.Name)
+	return true, nil
 }
 
-func ensureServiceDiscoveryDeleted(clients client.Producer, clusterName, namespace string, status reporter.Interface) error {
+func ensureServiceDiscoveryDeleted(clients client.Producer, clusterName, namespace string, options Options,
+	status reporter.Interface,
+) error {
 	defer status.End()
 
 	status.Start("Checking if the service discovery component is installed on cluster %q", clusterName)
@@ -242,15 +344,19 @@ func ensureServiceDiscoveryDeleted(clients client.Producer, clusterName, namespa
 
 	status.Start("Deleting the ServiceDiscovery resource - this may take some time")
 
-	err = ensureDeleted(clients, serviceDiscovery, status)
+	err = ensureDeleted(clients, serviceDiscovery, "ServiceDiscovery", options, status)
 
 	return status.Error(err, "Error deleting ServiceDiscovery resource %q", serviceDiscovery.Name)
 }
 
-func ensureDeleted(clients client.Producer, obj controller.Object, status reporter.Interface) error {
-	const maxWait = componentReadyTimeout + time.Second*30
+func ensureDeleted(clients client.Producer, obj controller.Object, kind string, options Options, status reporter.Interface) error {
 	const checkInterval = 2 * time.Second
 
+	maxWait := options.DeletionTimeout
+	if maxWait == 0 {
+		maxWait = componentReadyTimeout + time.Second*30
+	}
+
 	awaitDeleted := func() error {
 		//nolint:wrapcheck // No need to wrap
 		return wait.PollImmediate(checkInterval, maxWait, func() (bool, error) {
@@ -264,58 +370,120 @@ func ensureDeleted(clients client.Producer, obj controller.Object, status report
 	}
 
 	err := awaitDeleted()
+	if !errors.Is(err, wait.ErrWaitTimeout) {
+		return err
+	}
 
-	if errors.Is(err, wait.ErrWaitTimeout) {
-		labelSelector, err := deployment.GetPodLabelSelector(clients.ForKubernetes(), obj.GetNamespace())
-		if err != nil {
-			return errors.Wrap(err, "error obtaining the operator deployment label")
-		}
-
-		if labelSelector == "" {
-			status.Warning("The Submariner operator deployment does not exist so deletion of the resource was not completed - " +
-				"the resource will be force-deleted")
-		} else {
-			pods, err := clients.ForKubernetes().CoreV1().Pods(obj.GetNamespace()).List(context.TODO(), metav1.ListOptions{
-				LabelSelector: labelSelector,
-			})
-			if err != nil {
-				return errors.Wrap(err, "error listing pods")
-			}
+	finalizers, err := remainingFinalizers(clients, obj)
+	if err != nil || len(finalizers) == 0 {
+		return err
+	}
 
-			podStatusStr := ""
-			if len(pods.Items) == 0 {
-				podStatusStr = "does not exist"
-			} else {
-				if pods.Items[0].Status.Phase == corev1.PodRunning {
-					return status.Error(fmt.Errorf("the Submariner operator pod appears to be running but did not "+
-						"complete deletion of the resource. Please check the pod logs"), "")
-				}
+	status.Warning("The %s resource %s/%s is still pending deletion, blocked by the finalizers: %s", kind, obj.GetNamespace(),
+		obj.GetName(), strings.Join(finalizers, ", "))
 
-				podStatusStr = fmt.Sprintf("is not running (status is %q)", pods.Items[0].Status.Phase)
-			}
+	if options.Force {
+		status.Warning("Forcibly removing the finalizers from the %s resource - anything they guard may be left behind", kind)
+	} else {
+		// Without the operator, the cleanup finalizer will never be removed, so it's safe to remove it ourselves
+		canFinalize, err := operatorCanFinalize(clients, obj.GetNamespace(), status)
+		if err != nil {
+			return err
+		}
 
-			status.Warning("The Submariner operator pod %s so deletion of the resource was not completed - "+
-				"the resource will be force-deleted", podStatusStr)
+		if canFinalize {
+			return status.Error(fmt.Errorf("the Submariner operator pod appears to be running but did not "+
+				"complete deletion of the resource. Please check the pod logs, or use --force to remove the finalizers"), "")
 		}
 
-		err = finalizer.Remove(context.TODO(), resource.ForControllerClient(clients.ForGeneral(), obj.GetNamespace(), obj),
-			obj, names.CleanupFinalizer)
+		finalizers = []string{names.CleanupFinalizer}
+	}
+
+	for _, f := range finalizers {
+		err = finalizer.Remove(context.TODO(), resource.ForControllerClient(clients.ForGeneral(), obj.GetNamespace(), obj), obj, f)
 		if err != nil {
 			return err //nolint:wrapcheck // No need to wrap
 		}
+	}
+
+	err = awaitDeleted()
+	if !errors.Is(err, wait.ErrWaitTimeout) {
+		return err
+	}
+
+	finalizers, err = remainingFinalizers(clients, obj)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("the %s resource %s/%s is still pending deletion, blocked by the finalizers: %s", kind, obj.GetNamespace(),
+		obj.GetName(), strings.Join(finalizers, ", "))
+}
+
+// remainingFinalizers refreshes the given object and returns its finalizers; a missing object has none.
+func remainingFinalizers(clients client.Producer, obj controller.Object) ([]string, error) {
+	err := clients.ForGeneral().Get(context.TODO(), controller.ObjectKeyFromObject(obj), obj)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving resource %q", obj.GetName())
+	}
+
+	return obj.GetFinalizers(), nil
+}
+
+// operatorCanFinalize determines whether the Submariner operator is running and could thus still finalize resources in the
+// given namespace; if it isn't, the reason is reported.
+func operatorCanFinalize(clients client.Producer, namespace string, status reporter.Interface) (bool, error) {
+	labelSelector, err := deployment.GetPodLabelSelector(clients.ForKubernetes(), namespace)
+	if err != nil {
+		return false, errors.Wrap(err, "error obtaining the operator deployment label")
+	}
+
+	if labelSelector == "" {
+		status.Warning("The Submariner operator deployment does not exist so deletion of the resource was not completed - " +
+			"the resource will be force-deleted")
+
+		return false, nil
+	}
+
+	pods, err := clients.ForKubernetes().CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "error listing pods")
+	}
+
+	podStatusStr := ""
+	if len(pods.Items) == 0 {
+		podStatusStr = "does not exist"
+	} else {
+		if pods.Items[0].Status.Phase == corev1.PodRunning {
+			return true, nil
+		}
 
-		return awaitDeleted()
+		podStatusStr = fmt.Sprintf("is not running (status is %q)", pods.Items[0].Status.Phase)
 	}
 
-	return err
+	status.Warning("The Submariner operator pod %s so deletion of the resource was not completed - "+
+		"the resource will be force-deleted", podStatusStr)
+
+	return false, nil
 }
 
-func deleteBrokerIfUnused(clients client.Producer, namespace, clusterName string, status reporter.Interface) (bool, error) {
+func deleteBrokerIfUnused(clients client.Producer, namespace, clusterName string, retryBudget time.Duration,
+	status reporter.Interface,
+) (bool, error) {
 	if namespace == "" {
 		return true, nil
 	}
 
-	_, err := clients.ForKubernetes().CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	err := retryBrokerOperation(retryBudget, "Retrieving the broker namespace", status, func() error {
+		_, err := clients.ForKubernetes().CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+		return err //nolint:wrapcheck // No need to wrap
+	})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return true, nil
@@ -324,7 +492,7 @@ func deleteBrokerIfUnused(clients client.Producer, namespace, clusterName string
 		return false, status.Error(err, "Error retrieving broker namespace %q", namespace)
 	}
 
-	inUse, err := brokerInUse(clients.ForGeneral(), namespace, clusterName, status)
+	inUse, err := brokerInUse(clients.ForGeneral(), namespace, clusterName, retryBudget, status)
 	if err != nil {
 		return false, err
 	}
@@ -336,7 +504,10 @@ func deleteBrokerIfUnused(clients client.Producer, namespace, clusterName string
 	status.Start("Deleting the broker namespace %q", namespace)
 	defer status.End()
 
-	err = clients.ForKubernetes().CoreV1().Namespaces().Delete(context.TODO(), namespace, metav1.DeleteOptions{})
+	err = retryBrokerOperation(retryBudget, "Deleting the broker namespace", status, func() error {
+		//nolint:wrapcheck // No need to wrap
+		return clients.ForKubernetes().CoreV1().Namespaces().Delete(context.TODO(), namespace, metav1.DeleteOptions{})
+	})
 	if err != nil && !apierrors.IsNotFound(err) {
 		return false, status.Error(err, "Error deleting the broker namespace")
 	}
@@ -344,13 +515,17 @@ func deleteBrokerIfUnused(clients client.Producer, namespace, clusterName string
 	return true, nil
 }
 
-func brokerInUse(controllerClient controller.Client, namespace, clusterName string, status reporter.Interface) (bool, error) {
+func brokerInUse(controllerClient controller.Client, namespace, clusterName string, retryBudget time.Duration,
+	status reporter.Interface,
+) (bool, error) {
 	status.Start("Verifying broker namespace %q is not in use", namespace)
 	defer status.End()
 
 	endpoints := &submarinerv1.EndpointList{}
 
-	err := controllerClient.List(context.TODO(), endpoints, controller.InNamespace(namespace))
+	err := retryBrokerOperation(retryBudget, "Listing the broker Endpoints", status, func() error {
+		return controllerClient.List(context.TODO(), endpoints, controller.InNamespace(namespace)) //nolint:wrapcheck // No need to wrap
+	})
 	if err != nil {
 		return false, status.Error(err, "error retrieving Endpoints")
 	}
@@ -374,13 +549,17 @@ func brokerInUse(controllerClient controller.Client, namespace, clusterName stri
 	return false, nil
 }
 
-func findBrokerNamespace(controllerClient controller.Client, clusterName string, status reporter.Interface) (string, error) {
+func findBrokerNamespace(controllerClient controller.Client, clusterName string, retryBudget time.Duration,
+	status reporter.Interface,
+) (string, error) {
 	status.Start("Checking if the broker component is installed on cluster %q", clusterName)
 	defer status.End()
 
 	brokers := &operatorv1alpha1.BrokerList{}
 
-	err := controllerClient.List(context.TODO(), brokers, controller.InNamespace(metav1.NamespaceAll))
+	err := retryBrokerOperation(retryBudget, "Listing the broker resources", status, func() error {
+		return controllerClient.List(context.TODO(), brokers, controller.InNamespace(metav1.NamespaceAll)) //nolint:wrapcheck // No need to wrap
+	})
 	if err != nil && !meta.IsNoMatchError(err) {
 		return "", status.Error(err, "Error listing broker resources")
 	}
@@ -0,0 +1,320 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uninstall_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/uninstall"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	brokerNamespace = "submariner-k8s-broker"
+	otherFinalizer  = "example.com/other"
+)
+
+var _ = Describe("All", func() {
+	var (
+		kubeClient     *fake.Clientset
+		generalObjects []controllerClient.Object
+		brokerFailures *failingBrokerList
+		options        uninstall.Options
+		recorder       *cli.Recorder
+	)
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset(newNamespace(constants.OperatorNamespace))
+		generalObjects = nil
+		brokerFailures = &failingBrokerList{}
+		options = uninstall.Options{DeletionTimeout: 10 * time.Millisecond}
+		recorder = cli.NewRecorder(reporter.Silent())
+	})
+
+	run := func() error {
+		scheme := runtime.NewScheme()
+		Expect(operatorv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(submarinerv1.AddToScheme(scheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+
+		brokerFailures.Client = fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(generalObjects...).Build()
+
+		return uninstall.All(&client.DefaultProducer{KubeClient: kubeClient, GeneralClient: brokerFailures}, "east",
+			constants.OperatorNamespace, options, recorder.Reporter())
+	}
+
+	namespaceExists := func(name string) bool {
+		_, err := kubeClient.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+
+		Expect(err).To(Succeed())
+
+		return true
+	}
+
+	messages := func() string {
+		all := []string{}
+		for _, result := range recorder.Results() {
+			all = append(all, result.Messages...)
+		}
+
+		return strings.Join(all, "\n")
+	}
+
+	When("removing the Submariner namespace", func() {
+		BeforeEach(func() {
+			options.RemoveNamespace = true
+
+			operator := newDeployment(names.OperatorComponent, nil)
+			operatorReplicaSet := newOwned(&appsv1.ReplicaSet{}, "submariner-operator-5d8f7", ownerReference(operator, "Deployment"))
+			gateway := newOwned(&appsv1.DaemonSet{}, "submariner-gateway", metav1.OwnerReference{
+				APIVersion: operatorv1alpha1.GroupVersion.String(), Kind: "Submariner", Name: names.SubmarinerCrName, UID: "submariner",
+			})
+
+			Expect(kubeClient.Tracker().Add(operator)).To(Succeed())
+			Expect(kubeClient.Tracker().Add(operatorReplicaSet)).To(Succeed())
+			Expect(kubeClient.Tracker().Add(newOwned(&corev1.Pod{}, "submariner-operator-5d8f7-x7k2p",
+				ownerReference(operatorReplicaSet, "ReplicaSet")))).To(Succeed())
+			Expect(kubeClient.Tracker().Add(gateway)).To(Succeed())
+			Expect(kubeClient.Tracker().Add(newOwned(&corev1.Pod{}, "submariner-gateway-9qz4h",
+				ownerReference(gateway, "DaemonSet")))).To(Succeed())
+			Expect(kubeClient.Tracker().Add(&corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name: "submariner-metrics", Namespace: constants.OperatorNamespace, UID: "metrics",
+				Labels: map[string]string{constants.SubctlVersionLabel: "v0.15.0"},
+			}})).To(Succeed())
+		})
+
+		Context("and it only contains resources managed by Submariner", func() {
+			It("should remove it", func() {
+				Expect(run()).To(Succeed())
+				Expect(namespaceExists(constants.OperatorNamespace)).To(BeFalse())
+			})
+		})
+
+		DescribeTable("and it contains resources not managed by Submariner",
+			func(resource controllerClient.Object, expected string) {
+				Expect(kubeClient.Tracker().Add(resource)).To(Succeed())
+
+				err := run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expected))
+				Expect(namespaceExists(constants.OperatorNamespace)).To(BeTrue())
+			},
+			Entry("a pod", newOwned(&corev1.Pod{}, "nginx"), `Pod "nginx"`),
+			Entry("a pod named like a Submariner pod", newOwned(&corev1.Pod{}, "lighthouse-agent-abcde"),
+				`Pod "lighthouse-agent-abcde"`),
+			Entry("a deployment", newDeployment("submariner-dashboard", nil), `Deployment "submariner-dashboard"`),
+			Entry("a service", newOwned(&corev1.Service{}, "monitoring"), `Service "monitoring"`),
+		)
+	})
+
+	When("the deletion of the Submariner resource times out", func() {
+		var submariner *operatorv1alpha1.Submariner
+
+		BeforeEach(func() {
+			submariner = &operatorv1alpha1.Submariner{ObjectMeta: metav1.ObjectMeta{
+				Name:       names.SubmarinerCrName,
+				Namespace:  constants.OperatorNamespace,
+				Finalizers: []string{names.CleanupFinalizer, otherFinalizer},
+			}}
+			generalObjects = append(generalObjects, submariner)
+		})
+
+		submarinerExists := func() bool {
+			err := brokerFailures.Get(context.TODO(), controllerClient.ObjectKeyFromObject(submariner), &operatorv1alpha1.Submariner{})
+			if apierrors.IsNotFound(err) {
+				return false
+			}
+
+			Expect(err).To(Succeed())
+
+			return true
+		}
+
+		Context("and the operator is running", func() {
+			BeforeEach(func() {
+				operatorLabels := map[string]string{"name": names.OperatorComponent}
+
+				Expect(kubeClient.Tracker().Add(newDeployment(names.OperatorComponent, operatorLabels))).To(Succeed())
+
+				pod := newOwned(&corev1.Pod{}, "submariner-operator-x7k2p").(*corev1.Pod)
+				pod.Labels = operatorLabels
+				pod.Status.Phase = corev1.PodRunning
+				Expect(kubeClient.Tracker().Add(pod)).To(Succeed())
+			})
+
+			It("should fail and keep the finalizers", func() {
+				err := run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("--force"))
+				Expect(submarinerExists()).To(BeTrue())
+				Expect(messages()).To(ContainSubstring("blocked by the finalizers: %s, %s", names.CleanupFinalizer, otherFinalizer))
+			})
+
+			Context("with --force", func() {
+				It("should remove all the finalizers", func() {
+					options.Force = true
+
+					Expect(run()).To(Succeed())
+					Expect(submarinerExists()).To(BeFalse())
+					Expect(messages()).To(ContainSubstring("Forcibly removing the finalizers"))
+				})
+			})
+		})
+
+		Context("and the operator isn't running", func() {
+			It("should only remove the operator's finalizer", func() {
+				err := run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("blocked by the finalizers: %s", otherFinalizer))
+				Expect(submarinerExists()).To(BeTrue())
+			})
+
+			Context("and only the operator's finalizer blocks the deletion", func() {
+				It("should complete the deletion", func() {
+					submariner.Finalizers = []string{names.CleanupFinalizer}
+
+					Expect(run()).To(Succeed())
+					Expect(submarinerExists()).To(BeFalse())
+				})
+			})
+		})
+	})
+
+	When("the broker is installed", func() {
+		BeforeEach(func() {
+			Expect(kubeClient.Tracker().Add(newNamespace(brokerNamespace))).To(Succeed())
+			generalObjects = append(generalObjects, &operatorv1alpha1.Broker{
+				ObjectMeta: metav1.ObjectMeta{Name: "submariner-broker", Namespace: brokerNamespace},
+			})
+		})
+
+		Context("and broker operations fail transiently", func() {
+			BeforeEach(func() {
+				brokerFailures.failures = 1
+				brokerFailures.err = apierrors.NewServiceUnavailable("try again")
+			})
+
+			It("should retry them within the budget", func() {
+				options.BrokerRetryBudget = time.Minute
+
+				Expect(run()).To(Succeed())
+				Expect(brokerFailures.calls).To(Equal(2))
+				Expect(messages()).To(ContainSubstring("Listing the broker resources failed (attempt 1)"))
+				Expect(namespaceExists(brokerNamespace)).To(BeFalse())
+			})
+
+			It("should not retry them without a budget", func() {
+				Expect(run()).ToNot(Succeed())
+				Expect(brokerFailures.calls).To(Equal(1))
+				Expect(namespaceExists(brokerNamespace)).To(BeTrue())
+			})
+		})
+
+		Context("and broker operations fail permanently", func() {
+			It("should not retry them", func() {
+				options.BrokerRetryBudget = time.Minute
+				brokerFailures.failures = 3
+				brokerFailures.err = apierrors.NewForbidden(schema.GroupResource{Resource: "brokers"}, "", nil)
+
+				Expect(run()).ToNot(Succeed())
+				Expect(brokerFailures.calls).To(Equal(1))
+				Expect(namespaceExists(brokerNamespace)).To(BeTrue())
+			})
+		})
+
+		Context("and another cluster is still joined", func() {
+			It("should keep the broker", func() {
+				generalObjects = append(generalObjects, &submarinerv1.Endpoint{
+					ObjectMeta: metav1.ObjectMeta{Name: "west-submariner-cable-west-10-0-0-1", Namespace: brokerNamespace},
+					Spec:       submarinerv1.EndpointSpec{ClusterID: "west"},
+				})
+
+				Expect(run()).To(Succeed())
+				Expect(namespaceExists(brokerNamespace)).To(BeTrue())
+			})
+		})
+	})
+})
+
+// failingBrokerList fails the first listings of the Broker resources with the given error.
+type failingBrokerList struct {
+	controllerClient.Client
+	failures int
+	err      error
+	calls    int
+}
+
+func (c *failingBrokerList) List(ctx context.Context, list controllerClient.ObjectList, opts ...controllerClient.ListOption) error {
+	if _, ok := list.(*operatorv1alpha1.BrokerList); ok {
+		c.calls++
+		if c.calls <= c.failures {
+			return c.err
+		}
+	}
+
+	return c.Client.List(ctx, list, opts...) //nolint:wrapcheck // No need to wrap errors here.
+}
+
+func newNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func newDeployment(name string, podLabels map[string]string) *appsv1.Deployment {
+	deployment := newOwned(&appsv1.Deployment{}, name).(*appsv1.Deployment)
+	deployment.Spec.Template.Labels = podLabels
+
+	return deployment
+}
+
+func newOwned(obj controllerClient.Object, name string, owners ...metav1.OwnerReference) controllerClient.Object {
+	obj.SetName(name)
+	obj.SetNamespace(constants.OperatorNamespace)
+	obj.SetUID(types.UID(name))
+	obj.SetOwnerReferences(owners)
+
+	return obj
+}
+
+func ownerReference(owner controllerClient.Object, kind string) metav1.OwnerReference {
+	return metav1.OwnerReference{APIVersion: "apps/v1", Kind: kind, Name: owner.GetName(), UID: owner.GetUID()}
+}
@@ -0,0 +1,115 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uninstall
+
+import (
+	"context"
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/restconfig"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	submarinerClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// deleteClusterFromBroker deletes the Endpoint and Cluster resources of the given Submariner's cluster from its broker, so
+// that they don't linger on the other clusters. An unreachable broker isn't an error, but the resources then need to be
+// deleted manually.
+func deleteClusterFromBroker(kubeClient kubernetes.Interface, submariner *operatorv1alpha1.Submariner, retryBudget time.Duration,
+	status reporter.Interface,
+) {
+	clusterID := submariner.Spec.ClusterID
+	brokerNamespace := submariner.Spec.BrokerK8sRemoteNamespace
+
+	status.Start("Deleting the Endpoint and Cluster resources of cluster %q from the broker", clusterID)
+	defer status.End()
+
+	restConfig, _, err := restconfig.ForBroker(context.TODO(), kubeClient, submariner, nil)
+	if err == nil && restConfig != nil {
+		var clientset submarinerClientset.Interface
+
+		clientset, err = submarinerClientset.NewForConfig(restConfig)
+		if err == nil {
+			err = deleteBrokerResources(clientset, brokerNamespace, clusterID, retryBudget, status)
+		}
+	}
+
+	if err != nil {
+		status.Warning("Unable to clean up the broker: %v. The Endpoint and Cluster resources with cluster ID %q in the broker "+
+			"namespace %q need to be deleted manually", err, clusterID, brokerNamespace)
+	}
+}
+
+func deleteBrokerResources(clientset submarinerClientset.Interface, namespace, clusterID string, retryBudget time.Duration,
+	status reporter.Interface,
+) error {
+	endpoints := clientset.SubmarinerV1().Endpoints(namespace)
+
+	err := retryBrokerOperation(retryBudget, "Listing the broker Endpoints", status, func() error {
+		list, err := endpoints.List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err //nolint:wrapcheck // No need to wrap
+		}
+
+		for i := range list.Items {
+			if list.Items[i].Spec.ClusterID != clusterID {
+				continue
+			}
+
+			err = endpoints.Delete(context.TODO(), list.Items[i].Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err //nolint:wrapcheck // No need to wrap
+			}
+
+			status.Success("Deleted the broker Endpoint %q", list.Items[i].Name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	clusters := clientset.SubmarinerV1().Clusters(namespace)
+
+	return retryBrokerOperation(retryBudget, "Listing the broker Clusters", status, func() error {
+		list, err := clusters.List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err //nolint:wrapcheck // No need to wrap
+		}
+
+		for i := range list.Items {
+			if list.Items[i].Spec.ClusterID != clusterID {
+				continue
+			}
+
+			err = clusters.Delete(context.TODO(), list.Items[i].Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err //nolint:wrapcheck // No need to wrap
+			}
+
+			status.Success("Deleted the broker Cluster %q", list.Items[i].Name)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,182 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uninstall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const submarinerGroupSuffix = "submariner.io"
+
+type namespacedResource struct {
+	kind   string
+	object metav1.Object
+}
+
+// findNonSubmarinerResources returns the workloads and services in the given namespace which aren't managed by Submariner.
+// Resources are managed by Submariner if they carry the subctl version label, are owned by a Submariner resource (such as
+// the Submariner and ServiceDiscovery resources), are the operator deployment, or are owned by another managed resource
+// (such as the pods of a managed daemon set).
+func findNonSubmarinerResources(ctx context.Context, kubeClient kubernetes.Interface, namespace string) ([]string, error) {
+	resources, err := listWorkloads(ctx, kubeClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	others, err := listPodsJobsAndServices(ctx, kubeClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	resources = append(resources, others...)
+
+	managed := map[types.UID]bool{}
+
+	for found := true; found; {
+		found = false
+
+		for i := range resources {
+			if !managed[resources[i].object.GetUID()] && isManagedBySubmariner(&resources[i], managed) {
+				managed[resources[i].object.GetUID()] = true
+				found = true
+			}
+		}
+	}
+
+	foreign := []string{}
+
+	for i := range resources {
+		if !managed[resources[i].object.GetUID()] {
+			foreign = append(foreign, fmt.Sprintf("%s %q", resources[i].kind, resources[i].object.GetName()))
+		}
+	}
+
+	return foreign, nil
+}
+
+func isManagedBySubmariner(resource *namespacedResource, managed map[types.UID]bool) bool {
+	if _, ok := resource.object.GetLabels()[constants.SubctlVersionLabel]; ok {
+		return true
+	}
+
+	if resource.kind == "Deployment" && resource.object.GetName() == names.OperatorComponent {
+		return true
+	}
+
+	for _, owner := range resource.object.GetOwnerReferences() {
+		if managed[owner.UID] {
+			return true
+		}
+
+		groupVersion, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err == nil && (groupVersion.Group == submarinerGroupSuffix ||
+			strings.HasSuffix(groupVersion.Group, "."+submarinerGroupSuffix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func listWorkloads(ctx context.Context, kubeClient kubernetes.Interface, namespace string) ([]namespacedResource, error) {
+	resources := []namespacedResource{}
+	listOptions := metav1.ListOptions{}
+
+	deployments, err := kubeClient.AppsV1().Deployments(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing deployments")
+	}
+
+	for i := range deployments.Items {
+		resources = append(resources, namespacedResource{kind: "Deployment", object: &deployments.Items[i]})
+	}
+
+	replicaSets, err := kubeClient.AppsV1().ReplicaSets(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing replica sets")
+	}
+
+	for i := range replicaSets.Items {
+		resources = append(resources, namespacedResource{kind: "ReplicaSet", object: &replicaSets.Items[i]})
+	}
+
+	daemonSets, err := kubeClient.AppsV1().DaemonSets(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing daemon sets")
+	}
+
+	for i := range daemonSets.Items {
+		resources = append(resources, namespacedResource{kind: "DaemonSet", object: &daemonSets.Items[i]})
+	}
+
+	statefulSets, err := kubeClient.AppsV1().StatefulSets(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing stateful sets")
+	}
+
+	for i := range statefulSets.Items {
+		resources = append(resources, namespacedResource{kind: "StatefulSet", object: &statefulSets.Items[i]})
+	}
+
+	return resources, nil
+}
+
+func listPodsJobsAndServices(ctx context.Context, kubeClient kubernetes.Interface, namespace string) ([]namespacedResource, error) {
+	resources := []namespacedResource{}
+	listOptions := metav1.ListOptions{}
+
+	jobs, err := kubeClient.BatchV1().Jobs(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing jobs")
+	}
+
+	for i := range jobs.Items {
+		resources = append(resources, namespacedResource{kind: "Job", object: &jobs.Items[i]})
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing pods")
+	}
+
+	for i := range pods.Items {
+		resources = append(resources, namespacedResource{kind: "Pod", object: &pods.Items[i]})
+	}
+
+	services, err := kubeClient.CoreV1().Services(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing services")
+	}
+
+	for i := range services.Items {
+		resources = append(resources, namespacedResource{kind: "Service", object: &services.Items[i]})
+	}
+
+	return resources, nil
+}
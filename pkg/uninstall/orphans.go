@@ -0,0 +1,65 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uninstall
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	controller "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FindOrphanedSubmariners returns the Submariner resources which are in a namespace with no Submariner operator
+// deployment, and thus will never be reconciled or finalized.
+func FindOrphanedSubmariners(ctx context.Context, client controller.Client) ([]types.NamespacedName, error) {
+	submariners := &operatorv1alpha1.SubmarinerList{}
+
+	err := client.List(ctx, submariners, controller.InNamespace(metav1.NamespaceAll))
+	if meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing Submariner resources")
+	}
+
+	orphans := []types.NamespacedName{}
+
+	for i := range submariners.Items {
+		err = client.Get(ctx, controller.ObjectKey{
+			Namespace: submariners.Items[i].Namespace,
+			Name:      names.OperatorComponent,
+		}, &appsv1.Deployment{})
+
+		if apierrors.IsNotFound(err) {
+			orphans = append(orphans, types.NamespacedName{Namespace: submariners.Items[i].Namespace, Name: submariners.Items[i].Name})
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "error retrieving the operator deployment in namespace %q", submariners.Items[i].Namespace)
+		}
+	}
+
+	return orphans, nil
+}
@@ -0,0 +1,69 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uninstall
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var brokerRetryBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.5,
+	Steps:    math.MaxInt32,
+	Cap:      30 * time.Second,
+}
+
+// retryBrokerOperation runs the given broker operation, retrying it with a jittered backoff for up to the given budget as
+// long as it fails with a transient error (conflicts and server-side failures). Each failed attempt is reported, so that
+// progress remains visible on a flaky broker API. A zero budget disables retries.
+func retryBrokerOperation(budget time.Duration, description string, status reporter.Interface, operation func() error) error {
+	backoff := brokerRetryBackoff
+	deadline := time.Now().Add(budget)
+
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || !isTransientBrokerError(err) {
+			return err
+		}
+
+		delay := backoff.Step()
+		if time.Now().Add(delay).After(deadline) {
+			if attempt == 1 {
+				return err
+			}
+
+			return errors.Wrapf(err, "%s still failed after %d attempts", description, attempt)
+		}
+
+		status.Warning("%s failed (attempt %d): %s - retrying in %v", description, attempt, err, delay.Round(time.Second))
+		time.Sleep(delay)
+	}
+}
+
+func isTransientBrokerError(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsInternalError(err) || apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err)
+}